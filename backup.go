@@ -0,0 +1,187 @@
+package ddbomb
+
+import (
+	"time"
+)
+
+type BackupDetailsT struct {
+	BackupArn              string
+	BackupCreationDateTime EpochTime
+	BackupExpiryDateTime   EpochTime
+	BackupName             string
+	BackupSizeBytes        int64
+	BackupStatus           string // CREATING, DELETED or AVAILABLE
+	BackupType             string // USER, SYSTEM or AWS_BACKUP
+}
+
+type BackupSummaryT struct {
+	BackupArn              string
+	BackupCreationDateTime EpochTime
+	BackupExpiryDateTime   EpochTime
+	BackupName             string
+	BackupSizeBytes        int64
+	BackupStatus           string
+	BackupType             string
+	TableArn               string
+	TableId                string
+	TableName              string
+}
+
+type SourceTableDetailsT struct {
+	BillingMode           BillingMode
+	ItemCount             int64
+	KeySchema             []KeySchemaT
+	ProvisionedThroughput ProvisionedThroughputT
+	TableArn              string
+	TableCreationDateTime EpochTime
+	TableId               string
+	TableName             string
+	TableSizeBytes        int64
+}
+
+type BackupDescriptionT struct {
+	BackupDetails      BackupDetailsT
+	SourceTableDetails SourceTableDetailsT
+}
+
+type PointInTimeRecoveryDescriptionT struct {
+	EarliestRestorableDateTime EpochTime
+	LatestRestorableDateTime   EpochTime
+	PointInTimeRecoveryStatus  string // ENABLED or DISABLED
+}
+
+type ContinuousBackupsDescriptionT struct {
+	ContinuousBackupsStatus        string
+	PointInTimeRecoveryDescription PointInTimeRecoveryDescriptionT
+}
+
+type createBackupResponse struct {
+	BackupDetails BackupDetailsT
+}
+
+type backupDescriptionResponse struct {
+	BackupDescription BackupDescriptionT
+}
+
+type listBackupsResponse struct {
+	BackupSummaries        []BackupSummaryT
+	LastEvaluatedBackupArn string
+}
+
+type continuousBackupsResponse struct {
+	ContinuousBackupsDescription ContinuousBackupsDescriptionT
+}
+
+func (t *Table) CreateBackup(backupName string) (*BackupDetailsT, error) {
+	q := NewQuery(t)
+	q.AddBackupName(backupName)
+
+	var r createBackupResponse
+	if err := t.Server.queryServerInto(target("CreateBackup"), q, &r); err != nil {
+		return nil, err
+	}
+	return &r.BackupDetails, nil
+}
+
+func (t *Table) ListBackups() ([]BackupSummaryT, error) {
+	return t.Server.ListBackups(t.Name)
+}
+
+// ListBackups returns every backup of tableName, or of all tables when
+// tableName is empty, following LastEvaluatedBackupArn across pages.
+func (s *Server) ListBackups(tableName string) ([]BackupSummaryT, error) {
+	var backups []BackupSummaryT
+
+	q := NewEmptyQuery()
+	if tableName != "" {
+		q.addTableByName(tableName)
+	}
+
+	for {
+		var r listBackupsResponse
+		if err := s.queryServerInto(target("ListBackups"), q, &r); err != nil {
+			return nil, err
+		}
+		backups = append(backups, r.BackupSummaries...)
+
+		if r.LastEvaluatedBackupArn == "" {
+			return backups, nil
+		}
+		q.AddExclusiveStartBackupArn(r.LastEvaluatedBackupArn)
+	}
+}
+
+func (s *Server) DescribeBackup(backupArn string) (*BackupDescriptionT, error) {
+	return s.backupRequest("DescribeBackup", backupArn)
+}
+
+func (s *Server) DeleteBackup(backupArn string) (*BackupDescriptionT, error) {
+	return s.backupRequest("DeleteBackup", backupArn)
+}
+
+func (s *Server) backupRequest(operation, backupArn string) (*BackupDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.AddBackupArn(backupArn)
+
+	var r backupDescriptionResponse
+	if err := s.queryServerInto(target(operation), q, &r); err != nil {
+		return nil, err
+	}
+	return &r.BackupDescription, nil
+}
+
+// RestoreTableFromBackup creates targetTableName from the backup. The new
+// table is returned in the CREATING state; see WaitUntilTableActive.
+func (s *Server) RestoreTableFromBackup(backupArn, targetTableName string) (*TableDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.AddBackupArn(backupArn)
+	q.AddTargetTableName(targetTableName)
+
+	var r tableDescriptionResponse
+	if err := s.queryServerInto(target("RestoreTableFromBackup"), q, &r); err != nil {
+		return nil, err
+	}
+	return &r.TableDescription, nil
+}
+
+func (t *Table) RestoreToPointInTime(targetTableName string, restoreDateTime time.Time) (*TableDescriptionT, error) {
+	return t.Server.RestoreTableToPointInTime(t.Name, targetTableName, restoreDateTime)
+}
+
+// RestoreTableToPointInTime creates targetTableName from the state of
+// sourceTableName at restoreDateTime. A zero restoreDateTime restores the
+// latest restorable time.
+func (s *Server) RestoreTableToPointInTime(sourceTableName, targetTableName string, restoreDateTime time.Time) (*TableDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.AddSourceTableName(sourceTableName)
+	q.AddTargetTableName(targetTableName)
+	q.AddRestoreDateTime(restoreDateTime)
+
+	var r tableDescriptionResponse
+	if err := s.queryServerInto(target("RestoreTableToPointInTime"), q, &r); err != nil {
+		return nil, err
+	}
+	return &r.TableDescription, nil
+}
+
+// UpdateContinuousBackups turns point in time recovery on or off.
+func (t *Table) UpdateContinuousBackups(pointInTimeRecoveryEnabled bool) (*ContinuousBackupsDescriptionT, error) {
+	q := NewQuery(t)
+	q.AddPointInTimeRecoverySpecification(pointInTimeRecoveryEnabled)
+
+	var r continuousBackupsResponse
+	if err := t.Server.queryServerInto(target("UpdateContinuousBackups"), q, &r); err != nil {
+		return nil, err
+	}
+	return &r.ContinuousBackupsDescription, nil
+}
+
+func (t *Table) DescribeContinuousBackups() (*ContinuousBackupsDescriptionT, error) {
+	q := NewQuery(t)
+
+	var r continuousBackupsResponse
+	if err := t.Server.queryServerInto(target("DescribeContinuousBackups"), q, &r); err != nil {
+		return nil, err
+	}
+	return &r.ContinuousBackupsDescription, nil
+}
@@ -0,0 +1,53 @@
+package ddbomb_test
+
+import (
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type BackupSuite struct{}
+
+var _ = gocheck.Suite(&BackupSuite{})
+
+func (s *BackupSuite) TestListBackupsFollowsPages(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(cannedHandler(
+		`{"BackupSummaries": [{"BackupArn": "arn:1", "BackupName": "pre-migration", "BackupCreationDateTime": 1540000000}], "LastEvaluatedBackupArn": "arn:1"}`,
+		`{"BackupSummaries": [{"BackupArn": "arn:2", "BackupName": "nightly"}]}`,
+	), &requests))
+	defer ts.Close()
+
+	backups, err := server.NewTable("sites", ddbomb.PrimaryKey{}).ListBackups()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(backups, gocheck.HasLen, 2)
+	c.Check(backups[0].BackupName, gocheck.Equals, "pre-migration")
+	c.Check(backups[0].BackupCreationDateTime.Unix(), gocheck.Equals, int64(1540000000))
+	c.Check(backups[1].BackupArn, gocheck.Equals, "arn:2")
+	c.Check(requests, gocheck.DeepEquals, []string{
+		`DynamoDB_20120810.ListBackups {"TableName":"sites"}`,
+		`DynamoDB_20120810.ListBackups {"ExclusiveStartBackupArn":"arn:1","TableName":"sites"}`,
+	})
+}
+
+func (s *BackupSuite) TestRestoreTableToPointInTime(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(cannedHandler(
+		`{"TableDescription": {"TableName": "sites-restored", "TableStatus": "CREATING"}}`,
+	), &requests))
+	defer ts.Close()
+
+	table := server.NewTable("sites", ddbomb.PrimaryKey{})
+	desc, err := table.RestoreToPointInTime("sites-restored", time.Unix(1540000000, 0))
+	c.Assert(err, gocheck.IsNil)
+	c.Check(desc.TableStatus, gocheck.Equals, "CREATING")
+
+	_, err = table.RestoreToPointInTime("sites-latest", time.Time{})
+	c.Assert(err, gocheck.IsNil)
+
+	c.Check(requests, gocheck.DeepEquals, []string{
+		`DynamoDB_20120810.RestoreTableToPointInTime {"RestoreDateTime":1540000000,"SourceTableName":"sites","TargetTableName":"sites-restored"}`,
+		`DynamoDB_20120810.RestoreTableToPointInTime {"SourceTableName":"sites","TargetTableName":"sites-latest","UseLatestRestorableTime":true}`,
+	})
+}
@@ -0,0 +1,182 @@
+package ddbomb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type BreakerState string
+
+const (
+	BREAKER_CLOSED    BreakerState = "closed"
+	BREAKER_OPEN      BreakerState = "open"
+	BREAKER_HALF_OPEN BreakerState = "half-open"
+)
+
+// ErrCircuitOpen is returned without sending anything while the
+// CircuitBreaker of the Server is open.
+var ErrCircuitOpen = errors.New("Circuit breaker is open")
+
+// CircuitBreaker fails the operations of a Server fast once DynamoDB keeps
+// failing them, rather than piling up requests and retries. Set it as
+// Server.Breaker:
+//
+//	server.Breaker = &ddbomb.CircuitBreaker{}
+//
+// The breaker opens after FailureThreshold operations in a row fail with a
+// retryable error, throttles included, once their retries are exhausted.
+// While it is open operations fail with ErrCircuitOpen. After OpenTimeout
+// it is half-open and lets one operation through: the breaker closes if it
+// succeeds and opens again if it fails.
+type CircuitBreaker struct {
+	// FailureThreshold is how many failures in a row open the breaker; 5
+	// when zero.
+	FailureThreshold int
+
+	// OpenTimeout is how long the breaker stays open; 30 seconds when zero.
+	OpenTimeout time.Duration
+
+	// OnStateChange, when set, is called on every change of state.
+	OnStateChange func(from, to BreakerState)
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool              // a half-open breaker let an operation through
+	changes  [][2]BreakerState // not yet passed to OnStateChange
+}
+
+// State returns the state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	state := b.currentState(time.Now())
+	changes := b.takeChanges()
+	b.mu.Unlock()
+
+	b.notify(changes)
+	return state
+}
+
+// currentState moves an open breaker to half-open once OpenTimeout is over.
+func (b *CircuitBreaker) currentState(now time.Time) BreakerState {
+	if b.state == "" {
+		b.state = BREAKER_CLOSED
+	}
+	timeout := b.OpenTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	if b.state == BREAKER_OPEN && now.Sub(b.openedAt) >= timeout {
+		b.setState(BREAKER_HALF_OPEN, now)
+	}
+	return b.state
+}
+
+func (b *CircuitBreaker) setState(to BreakerState, now time.Time) {
+	if to == BREAKER_OPEN {
+		b.openedAt = now
+	}
+	b.probing = false
+	b.changes = append(b.changes, [2]BreakerState{b.state, to})
+	b.state = to
+}
+
+func (b *CircuitBreaker) takeChanges() [][2]BreakerState {
+	changes := b.changes
+	b.changes = nil
+	return changes
+}
+
+// notify calls OnStateChange, without holding the lock so that it may call
+// State.
+func (b *CircuitBreaker) notify(changes [][2]BreakerState) {
+	if b.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.OnStateChange(change[0], change[1])
+	}
+}
+
+// allow reports whether an operation may be sent, and whether it is the
+// probe of a half-open breaker, to be passed back to observe.
+func (b *CircuitBreaker) allow() (allowed, probe bool) {
+	b.mu.Lock()
+	allowed = true
+	switch b.currentState(time.Now()) {
+	case BREAKER_OPEN:
+		allowed = false
+	case BREAKER_HALF_OPEN:
+		allowed = !b.probing
+		probe = allowed
+		b.probing = true
+	}
+	changes := b.takeChanges()
+	b.mu.Unlock()
+
+	b.notify(changes)
+	return allowed, probe
+}
+
+// observe records the outcome of an operation allowed through. Operations
+// sent before the breaker opened say nothing once it is open, and only the
+// probe decides what becomes of a half-open breaker. Operations abandoned
+// by their caller say nothing about DynamoDB, so a probe abandoned lets the
+// half-open breaker try another.
+func (b *CircuitBreaker) observe(err error, probe bool) {
+	b.mu.Lock()
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		if probe {
+			b.probing = false
+		}
+		b.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	state := b.currentState(now)
+	threshold := b.FailureThreshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+
+	failed := err != nil && IsRetryable(err)
+	switch {
+	case state == BREAKER_HALF_OPEN && probe:
+		b.failures = 0
+		if failed {
+			b.setState(BREAKER_OPEN, now)
+		} else {
+			b.setState(BREAKER_CLOSED, now)
+		}
+	case state == BREAKER_CLOSED && !failed:
+		b.failures = 0
+	case state == BREAKER_CLOSED:
+		b.failures++
+		if b.failures >= threshold {
+			b.setState(BREAKER_OPEN, now)
+		}
+	}
+	changes := b.takeChanges()
+	b.mu.Unlock()
+
+	b.notify(changes)
+}
+
+// breakCircuit runs handler unless the CircuitBreaker of s is open.
+func (s *Server) breakCircuit(operation string, query *Query, handler Handler) ([]byte, error) {
+	if s.Breaker == nil {
+		return handler(operation, query)
+	}
+	allowed, probe := s.Breaker.allow()
+	if !allowed {
+		s.log(LOG_WARN, "Circuit breaker is open", "operation", operation, "tables", query.TableNames())
+		return nil, ErrCircuitOpen
+	}
+	response, err := handler(operation, query)
+	s.Breaker.observe(err, probe)
+	return response, err
+}
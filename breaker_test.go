@@ -0,0 +1,113 @@
+package ddbomb_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type BreakerSuite struct{}
+
+var _ = gocheck.Suite(&BreakerSuite{})
+
+func (s *BreakerSuite) breaker(server *ddbomb.Server, changes *[]string) {
+	server.Breaker = &ddbomb.CircuitBreaker{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+		OnStateChange: func(from, to ddbomb.BreakerState) {
+			*changes = append(*changes, string(from)+" -> "+string(to))
+		},
+	}
+}
+
+func (s *BreakerSuite) TestOpensAndCloses(c *gocheck.C) {
+	var requests, changes []string
+	ts, server := newTestServer(recordTargets(failingHandler(2, 500, "InternalServerError", describeSites), &requests))
+	defer ts.Close()
+	s.breaker(server, &changes)
+
+	for i := 0; i < 2; i++ {
+		_, err := server.DescribeTable("sites")
+		c.Check(err, gocheck.ErrorMatches, "InternalServerError: try again")
+	}
+	_, err := server.DescribeTable("sites")
+	c.Check(err, gocheck.Equals, ddbomb.ErrCircuitOpen)
+	c.Check(ddbomb.IsRetryable(err), gocheck.Equals, false)
+	c.Check(requests, gocheck.HasLen, 2)
+	c.Check(server.Breaker.State(), gocheck.Equals, ddbomb.BREAKER_OPEN)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(server.Breaker.State(), gocheck.Equals, ddbomb.BREAKER_CLOSED)
+	c.Check(changes, gocheck.DeepEquals, []string{"closed -> open", "open -> half-open", "half-open -> closed"})
+}
+
+func (s *BreakerSuite) TestFailedProbeReopens(c *gocheck.C) {
+	var changes []string
+	ts, server := newTestServer(failingHandler(10, 503, "ServiceUnavailable", describeSites))
+	defer ts.Close()
+	s.breaker(server, &changes)
+
+	server.DescribeTable("sites")
+	server.DescribeTable("sites")
+	time.Sleep(60 * time.Millisecond)
+	_, err := server.DescribeTable("sites")
+	c.Check(err, gocheck.ErrorMatches, "ServiceUnavailable: try again")
+	_, err = server.DescribeTable("sites")
+	c.Check(err, gocheck.Equals, ddbomb.ErrCircuitOpen)
+	c.Check(changes, gocheck.DeepEquals, []string{"closed -> open", "open -> half-open", "half-open -> open"})
+}
+
+func (s *BreakerSuite) TestClientErrorsDontCount(c *gocheck.C) {
+	var changes []string
+	ts, server := newTestServer(failingHandler(5, 400, "ValidationException", describeSites))
+	defer ts.Close()
+	s.breaker(server, &changes)
+
+	for i := 0; i < 5; i++ {
+		_, err := server.DescribeTable("sites")
+		c.Check(err, gocheck.ErrorMatches, "ValidationException: try again")
+	}
+	c.Check(server.Breaker.State(), gocheck.Equals, ddbomb.BREAKER_CLOSED)
+	c.Check(changes, gocheck.HasLen, 0)
+}
+
+func (s *BreakerSuite) TestLateSuccessDoesntClose(c *gocheck.C) {
+	var changes []string
+	started, release := make(chan struct{}), make(chan struct{})
+	ts, server := newTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "slow") {
+			close(started)
+			<-release
+			fmt.Fprint(w, describeSites)
+			return
+		}
+		w.WriteHeader(500)
+		fmt.Fprint(w, `{"__type":"com.amazonaws.dynamodb.v20120810#InternalServerError","message":"try again"}`)
+	}))
+	defer ts.Close()
+	s.breaker(server, &changes)
+
+	// Sent while the breaker is closed, answered once it is open.
+	done := make(chan error)
+	go func() {
+		_, err := server.DescribeTable("slow")
+		done <- err
+	}()
+	<-started
+	server.DescribeTable("sites")
+	server.DescribeTable("sites")
+	c.Check(server.Breaker.State(), gocheck.Equals, ddbomb.BREAKER_OPEN)
+
+	close(release)
+	c.Check(<-done, gocheck.IsNil)
+	c.Check(server.Breaker.State(), gocheck.Equals, ddbomb.BREAKER_OPEN)
+	c.Check(changes, gocheck.DeepEquals, []string{"closed -> open"})
+}
@@ -0,0 +1,68 @@
+package ddbomb
+
+import "encoding/json"
+
+// ConsumedCapacityT is the capacity an operation used on one table, as
+// returned when the request sets ReturnConsumedCapacity.
+type ConsumedCapacityT struct {
+	TableName          string
+	CapacityUnits      float64
+	ReadCapacityUnits  float64 `json:",omitempty"`
+	WriteCapacityUnits float64 `json:",omitempty"`
+}
+
+// Operations accepting ReturnConsumedCapacity.
+var consumedCapacityOperations = map[string]bool{
+	"GetItem":            true,
+	"PutItem":            true,
+	"UpdateItem":         true,
+	"DeleteItem":         true,
+	"Query":              true,
+	"Scan":               true,
+	"BatchGetItem":       true,
+	"BatchWriteItem":     true,
+	"TransactGetItems":   true,
+	"TransactWriteItems": true,
+}
+
+// ReportsConsumedCapacity reports whether the operation can return its
+// consumed capacity.
+func ReportsConsumedCapacity(operation string) bool {
+	return consumedCapacityOperations[operation]
+}
+
+// AddReturnConsumedCapacity sets ReturnConsumedCapacity to "TOTAL",
+// "INDEXES" or "NONE".
+func (q *Query) AddReturnConsumedCapacity(value string) {
+	q.buffer["ReturnConsumedCapacity"] = value
+}
+
+// RequestConsumedCapacity asks for the TOTAL consumed capacity when the
+// operation can report it and the query doesn't set ReturnConsumedCapacity.
+func (q *Query) RequestConsumedCapacity(operation string) {
+	if _, set := q.buffer["ReturnConsumedCapacity"]; !set && ReportsConsumedCapacity(operation) {
+		q.AddReturnConsumedCapacity("TOTAL")
+	}
+}
+
+// ParseConsumedCapacity returns the ConsumedCapacity of a response, which
+// is a single object for operations on one table and a list for batches.
+// It returns nil when the response has none.
+func ParseConsumedCapacity(response []byte) []ConsumedCapacityT {
+	var r struct {
+		ConsumedCapacity json.RawMessage
+	}
+	if json.Unmarshal(response, &r) != nil || len(r.ConsumedCapacity) == 0 {
+		return nil
+	}
+
+	var list []ConsumedCapacityT
+	if json.Unmarshal(r.ConsumedCapacity, &list) == nil {
+		return list
+	}
+	var one ConsumedCapacityT
+	if json.Unmarshal(r.ConsumedCapacity, &one) == nil && one.TableName != "" {
+		return []ConsumedCapacityT{one}
+	}
+	return nil
+}
@@ -4,6 +4,9 @@ type DataType string
 type ComparisonType string
 type ProjectionType string
 type KeyType string
+type BillingMode string
+type StreamViewType string
+type ShardIteratorType string
 
 const (
 	RANGE_KEY KeyType = "RANGE"
@@ -13,6 +16,19 @@ const (
 	PROJ_KEYS_ONLY                = "KEYS_ONLY"
 	PROJ_INCLUDE                  = "INCLUDE"
 
+	BILLING_PROVISIONED     BillingMode = "PROVISIONED"
+	BILLING_PAY_PER_REQUEST BillingMode = "PAY_PER_REQUEST"
+
+	STREAM_KEYS_ONLY          StreamViewType = "KEYS_ONLY"
+	STREAM_NEW_IMAGE          StreamViewType = "NEW_IMAGE"
+	STREAM_OLD_IMAGE          StreamViewType = "OLD_IMAGE"
+	STREAM_NEW_AND_OLD_IMAGES StreamViewType = "NEW_AND_OLD_IMAGES"
+
+	ITERATOR_TRIM_HORIZON          ShardIteratorType = "TRIM_HORIZON"
+	ITERATOR_LATEST                ShardIteratorType = "LATEST"
+	ITERATOR_AT_SEQUENCE_NUMBER    ShardIteratorType = "AT_SEQUENCE_NUMBER"
+	ITERATOR_AFTER_SEQUENCE_NUMBER ShardIteratorType = "AFTER_SEQUENCE_NUMBER"
+
 	STRING DataType = "S"
 	NUMBER          = "N"
 	BINARY          = "B"
@@ -0,0 +1,44 @@
+package ddbomb
+
+import (
+	"context"
+	"time"
+)
+
+// WithContext returns a copy of s whose operations run under ctx: they are
+// abandoned once ctx is done, and ctx reaches the middleware and the
+// Transport through Query.Context.
+func (s *Server) WithContext(ctx context.Context) *Server {
+	s2 := *s
+	s2.ctx = ctx
+	return &s2
+}
+
+func (s *Server) requestContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// sleep waits for d, or returns the error of ctx if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WithContext returns a copy of t whose operations run under ctx.
+func (t *Table) WithContext(ctx context.Context) *Table {
+	return &Table{t.Server.WithContext(ctx), t.Name, t.Key}
+}
+
+// WithContext returns a copy of s whose operations run under ctx.
+func (s *StreamsServer) WithContext(ctx context.Context) *StreamsServer {
+	return &StreamsServer{s.Server.WithContext(ctx)}
+}
@@ -0,0 +1,33 @@
+package ddbomb
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strconv"
+)
+
+// ErrCRC32Mismatch is matched by errors.Is when a response body doesn't
+// match its X-Amz-Crc32 header, such as when it was truncated in transit.
+// Like other transport errors it is retryable.
+var ErrCRC32Mismatch = errors.New("Response CRC32 mismatch")
+
+// checkCRC32 validates body against the X-Amz-Crc32 header of resp. Bodies
+// without the header, and those the transport decompressed, can't be
+// checked.
+func (s *Server) checkCRC32(resp *http.Response, body []byte) error {
+	header := resp.Header.Get("X-Amz-Crc32")
+	if s.DisableCRC32Check || header == "" || resp.Uncompressed {
+		return nil
+	}
+
+	expected, err := strconv.ParseUint(header, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: invalid header %q", ErrCRC32Mismatch, header)
+	}
+	if actual := crc32.ChecksumIEEE(body); uint32(expected) != actual {
+		return fmt.Errorf("%w: expected %d, got %d", ErrCRC32Mismatch, expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package ddbomb_test
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type CRC32Suite struct{}
+
+var _ = gocheck.Suite(&CRC32Suite{})
+
+const crcResponse = `{"Table": {"TableName": "sites", "TableStatus": "ACTIVE"}}`
+
+// corruptingHandler truncates the first corruptions responses, keeping the
+// checksum of the whole body.
+func corruptingHandler(corruptions int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amz-Crc32", fmt.Sprint(crc32.ChecksumIEEE([]byte(crcResponse))))
+		if corruptions > 0 {
+			corruptions--
+			fmt.Fprint(w, crcResponse[:20])
+			return
+		}
+		fmt.Fprint(w, crcResponse)
+	})
+}
+
+func (s *CRC32Suite) TestMismatchIsRetried(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(corruptingHandler(1), &requests))
+	defer ts.Close()
+	server.MaxRetries = 1
+
+	desc, err := server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(desc.TableStatus, gocheck.Equals, "ACTIVE")
+	c.Check(requests, gocheck.HasLen, 2)
+}
+
+func (s *CRC32Suite) TestMismatch(c *gocheck.C) {
+	ts, server := newTestServer(corruptingHandler(1))
+	defer ts.Close()
+
+	_, err := server.DescribeTable("sites")
+	c.Check(errors.Is(err, ddbomb.ErrCRC32Mismatch), gocheck.Equals, true)
+	c.Check(ddbomb.IsRetryable(err), gocheck.Equals, true)
+}
+
+func (s *CRC32Suite) TestDisableCRC32Check(c *gocheck.C) {
+	ts, server := newTestServer(corruptingHandler(1))
+	defer ts.Close()
+	server.DisableCRC32Check = true
+
+	_, err := server.DescribeTable("sites")
+	c.Check(errors.Is(err, ddbomb.ErrCRC32Mismatch), gocheck.Equals, false)
+}
+
+func (s *CRC32Suite) TestMissingHeader(c *gocheck.C) {
+	ts, server := cannedServer(crcResponse)
+	defer ts.Close()
+
+	desc, err := server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(desc.TableName, gocheck.Equals, "sites")
+}
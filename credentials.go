@@ -0,0 +1,266 @@
+package ddbomb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoCredentials is returned by CredentialsProviders which found nothing
+// to provide, such as EnvCredentials when the variables are unset.
+var ErrNoCredentials = errors.New("No AWS credentials found")
+
+// Credentials sign the requests sent to DynamoDB.
+type Credentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Expires is when temporary credentials stop working; the zero time
+	// for credentials which don't expire.
+	Expires time.Time
+}
+
+func (c Credentials) expiresWithin(window time.Duration) bool {
+	return !c.Expires.IsZero() && time.Now().Add(window).After(c.Expires)
+}
+
+// CredentialsProvider returns the credentials to sign a request with. It is
+// called for every request, so providers whose credentials are costly to
+// get should be wrapped in a CredentialsCache.
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// defaultCredentials is shared by the Servers without Credentials, so that
+// they share its cache.
+var defaultCredentials = DefaultCredentials()
+
+// credentials returns what to sign the next request with.
+func (s *Server) credentials(ctx context.Context) (Credentials, error) {
+	if s.Credentials == nil {
+		return defaultCredentials.Retrieve(ctx)
+	}
+	return s.Credentials.Retrieve(ctx)
+}
+
+// DefaultCredentials looks for credentials in the environment, then in the
+// shared credentials and config files. What it finds is kept for
+// DefaultMaxAge, so that rotated keys are picked up.
+func DefaultCredentials() CredentialsProvider {
+	cache := NewCredentialsCache(NewCredentialsChain(EnvCredentials{}, &SharedCredentials{}))
+	cache.MaxAge = DefaultMaxAge
+	return cache
+}
+
+// StaticCredentials always provides the same credentials.
+type StaticCredentials Credentials
+
+// NewStaticCredentials returns a provider of the given keys. token is only
+// needed for temporary credentials.
+func NewStaticCredentials(accessKeyId, secretAccessKey, token string) StaticCredentials {
+	return StaticCredentials{AccessKeyId: accessKeyId, SecretAccessKey: secretAccessKey, SessionToken: token}
+}
+
+func (c StaticCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	if c.AccessKeyId == "" || c.SecretAccessKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return Credentials(c), nil
+}
+
+// EnvCredentials reads AWS_ACCESS_KEY_ID (or AWS_ACCESS_KEY),
+// AWS_SECRET_ACCESS_KEY (or AWS_SECRET_KEY) and AWS_SESSION_TOKEN.
+type EnvCredentials struct{}
+
+func (EnvCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	c := Credentials{
+		AccessKeyId:     firstEnv("AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY"),
+		SecretAccessKey: firstEnv("AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if c.AccessKeyId == "" || c.SecretAccessKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return c, nil
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// SharedCredentials reads the keys of a profile from the shared credentials
+// file, falling back to the shared config file, as the AWS CLI does. The
+// files are read again on every Retrieve.
+type SharedCredentials struct {
+	// Profile defaults to $AWS_PROFILE, then to "default".
+	Profile string
+
+	// CredentialsFile defaults to $AWS_SHARED_CREDENTIALS_FILE, then to
+	// ~/.aws/credentials.
+	CredentialsFile string
+
+	// ConfigFile defaults to $AWS_CONFIG_FILE, then to ~/.aws/config.
+	ConfigFile string
+}
+
+func (p *SharedCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	profile := p.Profile
+	if profile == "" {
+		profile = firstEnv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	// The config file names its sections "profile NAME", except "default".
+	configSection := "profile " + profile
+	if profile == "default" {
+		configSection = profile
+	}
+
+	for _, source := range []struct{ path, section string }{
+		{sharedFile(p.CredentialsFile, "AWS_SHARED_CREDENTIALS_FILE", "credentials"), profile},
+		{sharedFile(p.ConfigFile, "AWS_CONFIG_FILE", "config"), configSection},
+	} {
+		values, err := readINISection(source.path, source.section)
+		if err != nil {
+			return Credentials{}, err
+		}
+		c := Credentials{
+			AccessKeyId:     values["aws_access_key_id"],
+			SecretAccessKey: values["aws_secret_access_key"],
+			SessionToken:    values["aws_session_token"],
+		}
+		if c.AccessKeyId != "" && c.SecretAccessKey != "" {
+			return c, nil
+		}
+	}
+	return Credentials{}, fmt.Errorf("%w for profile %s", ErrNoCredentials, profile)
+}
+
+func sharedFile(path, env, name string) string {
+	if path == "" {
+		path = os.Getenv(env)
+	}
+	if path == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, ".aws", name)
+		}
+	}
+	return path
+}
+
+// readINISection returns the keys of section in the INI file at path, or
+// nothing if the file doesn't exist.
+func readINISection(path, section string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			current = strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+		case current == section:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return values, scanner.Err()
+}
+
+// CredentialsChain provides the credentials of the first of its providers
+// that has some.
+type CredentialsChain []CredentialsProvider
+
+func NewCredentialsChain(providers ...CredentialsProvider) CredentialsChain {
+	return CredentialsChain(providers)
+}
+
+func (chain CredentialsChain) Retrieve(ctx context.Context) (Credentials, error) {
+	for _, provider := range chain {
+		c, err := provider.Retrieve(ctx)
+		if err == nil {
+			return c, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			return Credentials{}, err
+		}
+	}
+	return Credentials{}, ErrNoCredentials
+}
+
+// DefaultExpiryWindow is how long before they expire a CredentialsCache
+// refreshes credentials, unless told otherwise.
+const DefaultExpiryWindow = 5 * time.Minute
+
+// DefaultMaxAge is how long DefaultCredentials keeps credentials without an
+// expiry time.
+const DefaultMaxAge = 5 * time.Minute
+
+// CredentialsCache keeps the credentials of Provider until ExpiryWindow
+// before they expire, so that requests in flight don't see them expire.
+// Credentials without an expiry time are kept for MaxAge, or until
+// Invalidate is called when MaxAge is zero.
+type CredentialsCache struct {
+	Provider     CredentialsProvider
+	ExpiryWindow time.Duration
+	MaxAge       time.Duration
+
+	mu        sync.Mutex
+	cached    *Credentials
+	retrieved time.Time
+}
+
+func NewCredentialsCache(provider CredentialsProvider) *CredentialsCache {
+	return &CredentialsCache{Provider: provider, ExpiryWindow: DefaultExpiryWindow}
+}
+
+func (c *CredentialsCache) Retrieve(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && !c.cached.expiresWithin(c.ExpiryWindow) &&
+		!(c.cached.Expires.IsZero() && c.MaxAge > 0 && time.Since(c.retrieved) >= c.MaxAge) {
+		return *c.cached, nil
+	}
+	creds, err := c.Provider.Retrieve(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.cached = &creds
+	c.retrieved = time.Now()
+	return creds, nil
+}
+
+// Invalidate makes the next Retrieve ask Provider again.
+func (c *CredentialsCache) Invalidate() {
+	c.mu.Lock()
+	c.cached = nil
+	c.mu.Unlock()
+}
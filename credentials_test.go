@@ -0,0 +1,189 @@
+package ddbomb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type CredentialsSuite struct {
+	env map[string]string // saved by setenv, restored on tear down
+}
+
+var _ = gocheck.Suite(&CredentialsSuite{})
+
+func (s *CredentialsSuite) SetUpTest(c *gocheck.C) {
+	s.env = map[string]string{}
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY",
+		"AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_SHARED_CREDENTIALS_FILE", "AWS_CONFIG_FILE"} {
+		s.setenv(name, "")
+	}
+}
+
+func (s *CredentialsSuite) TearDownTest(c *gocheck.C) {
+	for name, value := range s.env {
+		os.Setenv(name, value)
+	}
+}
+
+func (s *CredentialsSuite) setenv(name, value string) {
+	if _, saved := s.env[name]; !saved {
+		s.env[name] = os.Getenv(name)
+	}
+	os.Setenv(name, value)
+}
+
+// countingProvider provides credentials expiring after ttl, or never when
+// it is zero, counting how many times it was asked.
+type countingProvider struct {
+	ttl   time.Duration
+	calls int
+}
+
+func (p *countingProvider) Retrieve(ctx context.Context) (ddbomb.Credentials, error) {
+	p.calls++
+	creds := ddbomb.Credentials{
+		AccessKeyId:     fmt.Sprint("KEY", p.calls),
+		SecretAccessKey: "SECRET",
+		SessionToken:    fmt.Sprint("TOKEN", p.calls),
+	}
+	if p.ttl > 0 {
+		creds.Expires = time.Now().Add(p.ttl)
+	}
+	return creds, nil
+}
+
+func (s *CredentialsSuite) TestEnvCredentials(c *gocheck.C) {
+	_, err := ddbomb.EnvCredentials{}.Retrieve(context.Background())
+	c.Check(errors.Is(err, ddbomb.ErrNoCredentials), gocheck.Equals, true)
+
+	s.setenv("AWS_ACCESS_KEY", "ENV_KEY")
+	s.setenv("AWS_SECRET_ACCESS_KEY", "ENV_SECRET")
+	s.setenv("AWS_SESSION_TOKEN", "ENV_TOKEN")
+	creds, err := ddbomb.EnvCredentials{}.Retrieve(context.Background())
+	c.Assert(err, gocheck.IsNil)
+	c.Check(creds, gocheck.DeepEquals, ddbomb.Credentials{AccessKeyId: "ENV_KEY", SecretAccessKey: "ENV_SECRET", SessionToken: "ENV_TOKEN"})
+}
+
+func (s *CredentialsSuite) TestSharedCredentials(c *gocheck.C) {
+	dir := c.MkDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+	c.Assert(os.WriteFile(credentialsFile, []byte(`
+# comment
+[default]
+aws_access_key_id = DEFAULT_KEY
+aws_secret_access_key = DEFAULT_SECRET
+
+[work]
+aws_access_key_id=WORK_KEY
+aws_secret_access_key=WORK_SECRET
+aws_session_token=WORK_TOKEN
+`), 0600), gocheck.IsNil)
+	c.Assert(os.WriteFile(configFile, []byte(`
+[default]
+region = us-west-2
+
+[profile  ops]
+aws_access_key_id = OPS_KEY
+aws_secret_access_key = OPS_SECRET
+`), 0600), gocheck.IsNil)
+	s.setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+
+	provider := &ddbomb.SharedCredentials{ConfigFile: configFile}
+	creds, err := provider.Retrieve(context.Background())
+	c.Assert(err, gocheck.IsNil)
+	c.Check(creds, gocheck.DeepEquals, ddbomb.Credentials{AccessKeyId: "DEFAULT_KEY", SecretAccessKey: "DEFAULT_SECRET"})
+
+	s.setenv("AWS_PROFILE", "work")
+	creds, err = provider.Retrieve(context.Background())
+	c.Assert(err, gocheck.IsNil)
+	c.Check(creds.SessionToken, gocheck.Equals, "WORK_TOKEN")
+
+	provider.Profile = "ops"
+	creds, err = provider.Retrieve(context.Background())
+	c.Assert(err, gocheck.IsNil)
+	c.Check(creds.AccessKeyId, gocheck.Equals, "OPS_KEY")
+
+	provider.Profile = "missing"
+	_, err = provider.Retrieve(context.Background())
+	c.Check(err, gocheck.ErrorMatches, "No AWS credentials found for profile missing")
+	c.Check(errors.Is(err, ddbomb.ErrNoCredentials), gocheck.Equals, true)
+}
+
+func (s *CredentialsSuite) TestCredentialsChain(c *gocheck.C) {
+	chain := ddbomb.NewCredentialsChain(ddbomb.EnvCredentials{}, ddbomb.NewStaticCredentials("STATIC_KEY", "STATIC_SECRET", ""))
+	creds, err := chain.Retrieve(context.Background())
+	c.Assert(err, gocheck.IsNil)
+	c.Check(creds.AccessKeyId, gocheck.Equals, "STATIC_KEY")
+
+	s.setenv("AWS_ACCESS_KEY_ID", "ENV_KEY")
+	s.setenv("AWS_SECRET_KEY", "ENV_SECRET")
+	creds, err = chain.Retrieve(context.Background())
+	c.Assert(err, gocheck.IsNil)
+	c.Check(creds.AccessKeyId, gocheck.Equals, "ENV_KEY")
+
+	_, err = ddbomb.NewCredentialsChain().Retrieve(context.Background())
+	c.Check(err, gocheck.Equals, ddbomb.ErrNoCredentials)
+}
+
+func (s *CredentialsSuite) TestCacheRefreshesBeforeExpiry(c *gocheck.C) {
+	longLived := &countingProvider{ttl: time.Hour}
+	cache := ddbomb.NewCredentialsCache(longLived)
+	for i := 0; i < 3; i++ {
+		creds, err := cache.Retrieve(context.Background())
+		c.Assert(err, gocheck.IsNil)
+		c.Check(creds.AccessKeyId, gocheck.Equals, "KEY1")
+	}
+	cache.Invalidate()
+	creds, _ := cache.Retrieve(context.Background())
+	c.Check(creds.AccessKeyId, gocheck.Equals, "KEY2")
+
+	// Within the expiry window, so refreshed every time.
+	shortLived := &countingProvider{ttl: time.Minute}
+	cache = ddbomb.NewCredentialsCache(shortLived)
+	cache.Retrieve(context.Background())
+	cache.Retrieve(context.Background())
+	c.Check(shortLived.calls, gocheck.Equals, 2)
+}
+
+func (s *CredentialsSuite) TestCacheMaxAge(c *gocheck.C) {
+	static := &countingProvider{}
+	cache := ddbomb.NewCredentialsCache(static)
+	cache.Retrieve(context.Background())
+	cache.Retrieve(context.Background())
+	c.Check(static.calls, gocheck.Equals, 1)
+
+	cache.MaxAge = 50 * time.Millisecond
+	time.Sleep(60 * time.Millisecond)
+	creds, _ := cache.Retrieve(context.Background())
+	c.Check(creds.AccessKeyId, gocheck.Equals, "KEY2")
+	cache.Retrieve(context.Background())
+	c.Check(static.calls, gocheck.Equals, 2)
+}
+
+func (s *CredentialsSuite) TestServerSignsWithProvider(c *gocheck.C) {
+	var tokens []string
+	ts, server := newTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("X-Amz-Security-Token"))
+		fmt.Fprint(w, `{"Table": {"TableName": "sites", "TableStatus": "ACTIVE"}}`)
+	}))
+	defer ts.Close()
+
+	server.Credentials = &countingProvider{ttl: time.Hour}
+	server.DescribeTable("sites")
+	server.DescribeTable("sites")
+	c.Check(tokens, gocheck.DeepEquals, []string{"TOKEN1", "TOKEN2"})
+
+	server.Credentials = ddbomb.NewStaticCredentials("", "", "")
+	_, err := server.DescribeTable("sites")
+	c.Check(err, gocheck.Equals, ddbomb.ErrNoCredentials)
+	c.Check(ddbomb.IsRetryable(err), gocheck.Equals, false)
+}
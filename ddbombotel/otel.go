@@ -0,0 +1,99 @@
+// Package ddbombotel traces ddbomb operations with OpenTelemetry:
+//
+//	server.Use(ddbombotel.Middleware())
+//	table := server.NewTable(name, key).WithContext(ctx)
+//
+// Every operation then gets a client span, child of the span in ctx.
+package ddbombotel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ryansb/dynamodbomb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/ryansb/dynamodbomb/ddbombotel"
+
+type config struct {
+	provider trace.TracerProvider
+}
+
+// Option configures Middleware.
+type Option func(*config)
+
+// WithTracerProvider sets the provider spans are created with instead of
+// the global one.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// Middleware returns a ddbomb.Middleware starting a span named after the
+// operation, such as "DynamoDB.PutItem", with these attributes:
+//
+//	db.system                         "dynamodb"
+//	db.operation                      the operation name
+//	aws.dynamodb.table_names          the tables the operation works on
+//	aws.dynamodb.consumed_capacity    the ConsumedCapacity entries, as JSON
+//	aws.dynamodb.retry_count          retries made, see Server.MaxRetries
+//	error.type                        the DynamoDB error code on failure
+//
+// The span's context is passed down through Query.Context, so that an
+// instrumented Server.Transport continues the trace. Operations which can
+// report their consumed capacity are asked to, unless the query already
+// sets ReturnConsumedCapacity.
+func Middleware(opts ...Option) ddbomb.Middleware {
+	cfg := config{provider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tracer := cfg.provider.Tracer(instrumentationName)
+
+	return func(next ddbomb.Handler) ddbomb.Handler {
+		return func(operation string, query *ddbomb.Query) ([]byte, error) {
+			ctx, span := tracer.Start(query.Context(), "DynamoDB."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("db.system", "dynamodb"),
+					attribute.String("db.operation", operation),
+					attribute.StringSlice("aws.dynamodb.table_names", query.TableNames()),
+				))
+			defer span.End()
+
+			query.RequestConsumedCapacity(operation)
+
+			response, err := next(operation, query.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("aws.dynamodb.retry_count", query.Retries()))
+			if capacity := ddbomb.ParseConsumedCapacity(response); len(capacity) > 0 {
+				entries := make([]string, len(capacity))
+				for i, c := range capacity {
+					entry, _ := json.Marshal(c)
+					entries[i] = string(entry)
+				}
+				span.SetAttributes(attribute.StringSlice("aws.dynamodb.consumed_capacity", entries))
+			}
+			if err != nil {
+				span.SetAttributes(attribute.String("error.type", errorType(err)))
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return response, err
+		}
+	}
+}
+
+func errorType(err error) string {
+	var ddbErr *ddbomb.Error
+	if errors.As(err, &ddbErr) && ddbErr.Code != "" {
+		return ddbErr.Code
+	}
+	return fmt.Sprintf("%T", err)
+}
@@ -0,0 +1,141 @@
+package ddbombotel_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ryansb/dynamodbomb"
+	"github.com/ryansb/dynamodbomb/ddbombotel"
+	"github.com/ryansb/dynamodbomb/ddbombtest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"launchpad.net/gocheck"
+)
+
+func Test(t *testing.T) {
+	gocheck.TestingT(t)
+}
+
+type TracingSuite struct {
+	ts       *httptest.Server
+	server   *ddbomb.Server
+	recorder *tracetest.SpanRecorder
+	provider *sdktrace.TracerProvider
+	failures int // requests answered with a 500 before reaching the fake
+}
+
+var _ = gocheck.Suite(&TracingSuite{})
+
+func (s *TracingSuite) SetUpTest(c *gocheck.C) {
+	fake := ddbombtest.NewFake()
+	s.failures = 0
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(500)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#InternalServerError","message":"oops"}`))
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+
+	s.recorder = tracetest.NewSpanRecorder()
+	s.provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder))
+	s.server = ddbomb.NewLocalServer(s.ts.URL)
+	s.server.MaxRetries = 2
+	s.server.Use(ddbombotel.Middleware(ddbombotel.WithTracerProvider(s.provider)))
+
+	_, err := s.server.CreateTable(ddbomb.TableDescriptionT{
+		TableName:            "sites",
+		BillingMode:          ddbomb.BILLING_PAY_PER_REQUEST,
+		AttributeDefinitions: []ddbomb.AttributeDefinitionT{{Name: "Domain", Type: ddbomb.STRING}},
+		KeySchema:            []ddbomb.KeySchemaT{{AttributeName: "Domain", KeyType: ddbomb.HASH_KEY}},
+	})
+	c.Assert(err, gocheck.IsNil)
+}
+
+func (s *TracingSuite) TearDownTest(c *gocheck.C) {
+	s.ts.Close()
+}
+
+func (s *TracingSuite) table() *ddbomb.Table {
+	return s.server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+}
+
+// lastSpan returns the attributes of the most recent span, which must be
+// named name.
+func (s *TracingSuite) lastSpan(c *gocheck.C, name string) (sdktrace.ReadOnlySpan, map[attribute.Key]attribute.Value) {
+	spans := s.recorder.Ended()
+	c.Assert(spans, gocheck.Not(gocheck.HasLen), 0)
+	span := spans[len(spans)-1]
+	c.Assert(span.Name(), gocheck.Equals, name)
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return span, attrs
+}
+
+func (s *TracingSuite) TestSpanIsChildOfContext(c *gocheck.C) {
+	ctx, parent := s.provider.Tracer("test").Start(context.Background(), "handler")
+	_, err := s.table().WithContext(ctx).PutItem("example.com", "", []ddbomb.Attribute{
+		*ddbomb.NewStringAttribute("Owner", "ann"),
+	})
+	parent.End()
+	c.Assert(err, gocheck.IsNil)
+
+	spans := s.recorder.Ended()
+	c.Assert(spans, gocheck.HasLen, 3) // CreateTable, PutItem, handler
+	put := spans[1]
+	c.Check(put.Name(), gocheck.Equals, "DynamoDB.PutItem")
+	c.Check(put.Parent().SpanID(), gocheck.Equals, parent.SpanContext().SpanID())
+	c.Check(spans[0].Parent().IsValid(), gocheck.Equals, false)
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range put.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	c.Check(attrs["db.system"].AsString(), gocheck.Equals, "dynamodb")
+	c.Check(attrs["db.operation"].AsString(), gocheck.Equals, "PutItem")
+	c.Check(attrs["aws.dynamodb.table_names"].AsStringSlice(), gocheck.DeepEquals, []string{"sites"})
+	c.Check(attrs["aws.dynamodb.consumed_capacity"].AsStringSlice(), gocheck.DeepEquals, []string{
+		`{"TableName":"sites","CapacityUnits":1}`,
+	})
+	c.Check(attrs["aws.dynamodb.retry_count"].AsInt64(), gocheck.Equals, int64(0))
+}
+
+func (s *TracingSuite) TestRetriesAreCounted(c *gocheck.C) {
+	s.failures = 1
+	_, err := s.table().GetItem(&ddbomb.Key{HashKey: "example.com"})
+	c.Check(err, gocheck.Equals, ddbomb.ErrNotFound)
+
+	span, attrs := s.lastSpan(c, "DynamoDB.GetItem")
+	c.Check(span.Status().Code, gocheck.Equals, codes.Unset)
+	c.Check(attrs["aws.dynamodb.retry_count"].AsInt64(), gocheck.Equals, int64(1))
+}
+
+func (s *TracingSuite) TestErrorCode(c *gocheck.C) {
+	_, err := s.server.DescribeTable("missing")
+	c.Assert(err, gocheck.NotNil)
+
+	span, attrs := s.lastSpan(c, "DynamoDB.DescribeTable")
+	c.Check(span.Status().Code, gocheck.Equals, codes.Error)
+	c.Check(attrs["error.type"].AsString(), gocheck.Equals, "ResourceNotFoundException")
+	c.Check(attrs["aws.dynamodb.table_names"].AsStringSlice(), gocheck.DeepEquals, []string{"missing"})
+}
+
+func (s *TracingSuite) TestBatchTableNames(c *gocheck.C) {
+	batch := s.table().BatchWriteItems(map[string][][]ddbomb.Attribute{
+		"Put": {{*ddbomb.NewStringAttribute("Domain", "example.com")}},
+	})
+	_, err := batch.Execute()
+	c.Assert(err, gocheck.IsNil)
+
+	_, attrs := s.lastSpan(c, "DynamoDB.BatchWriteItem")
+	c.Check(attrs["aws.dynamodb.table_names"].AsStringSlice(), gocheck.DeepEquals, []string{"sites"})
+}
@@ -0,0 +1,151 @@
+// Package ddbombprom exports Prometheus metrics about the DynamoDB calls
+// made through ddbomb:
+//
+//	metrics := ddbombprom.NewCollector("myapp")
+//	prometheus.MustRegister(metrics)
+//	server.Use(metrics.Middleware())
+//
+// Every metric is labeled by table and operation. An operation spanning
+// several tables, such as BatchWriteItem, counts once for each of them, and
+// one without a table, such as ListTables, is labeled with an empty table.
+package ddbombprom
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/ryansb/dynamodbomb"
+)
+
+var labels = []string{"table", "operation"}
+
+// Collector is a prometheus.Collector for the metrics of one or more
+// ddbomb.Servers.
+type Collector struct {
+	requests    *prometheus.CounterVec
+	latency     *prometheus.HistogramVec
+	throttles   *prometheus.CounterVec
+	retries     *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+	unprocessed *prometheus.CounterVec
+	capacity    *prometheus.CounterVec
+}
+
+// NewCollector returns a Collector whose metric names start with
+// namespace, followed by "dynamodb_".
+func NewCollector(namespace string) *Collector {
+	counter := func(name, help string, extra ...string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dynamodb",
+			Name:      name,
+			Help:      help,
+		}, append(append([]string(nil), labels...), extra...))
+	}
+
+	return &Collector{
+		requests: counter("requests_total", "Operations sent to DynamoDB."),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "dynamodb",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken by operations, retries included.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		throttles:   counter("throttles_total", "Attempts rejected because of throttling."),
+		retries:     counter("retries_total", "Attempts resent after a retryable error."),
+		errors:      counter("errors_total", "Operations that failed, by DynamoDB error code.", "code"),
+		unprocessed: counter("unprocessed_items_total", "Items or keys left unprocessed by batch operations."),
+		capacity:    counter("consumed_capacity_units_total", "Capacity units consumed, as reported by DynamoDB."),
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.requests, c.latency, c.throttles, c.retries, c.errors, c.unprocessed, c.capacity}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+// Middleware returns the ddbomb.Middleware recording the metrics. Operations
+// which can report their consumed capacity are asked to, unless the query
+// already sets ReturnConsumedCapacity.
+func (c *Collector) Middleware() ddbomb.Middleware {
+	return func(next ddbomb.Handler) ddbomb.Handler {
+		return func(operation string, query *ddbomb.Query) ([]byte, error) {
+			query.RequestConsumedCapacity(operation)
+
+			start := time.Now()
+			response, err := next(operation, query)
+			elapsed := time.Since(start).Seconds()
+
+			code := errorCode(err)
+			tables := query.TableNames()
+			if len(tables) == 0 {
+				// Operations such as ListTables and those of DynamoDB Streams.
+				tables = []string{""}
+			}
+			for _, table := range tables {
+				c.requests.WithLabelValues(table, operation).Inc()
+				c.latency.WithLabelValues(table, operation).Observe(elapsed)
+				c.throttles.WithLabelValues(table, operation).Add(float64(query.Throttles()))
+				c.retries.WithLabelValues(table, operation).Add(float64(query.Retries()))
+				if err != nil {
+					c.errors.WithLabelValues(table, operation, code).Inc()
+				}
+			}
+
+			for table, count := range unprocessedItems(response) {
+				c.unprocessed.WithLabelValues(table, operation).Add(float64(count))
+			}
+			for _, consumed := range ddbomb.ParseConsumedCapacity(response) {
+				c.capacity.WithLabelValues(consumed.TableName, operation).Add(consumed.CapacityUnits)
+			}
+			return response, err
+		}
+	}
+}
+
+// errorCode is the DynamoDB error code of err, or "other" for errors that
+// didn't come from DynamoDB.
+func errorCode(err error) string {
+	var ddbErr *ddbomb.Error
+	if errors.As(err, &ddbErr) && ddbErr.Code != "" {
+		return ddbErr.Code
+	}
+	return "other"
+}
+
+// unprocessedItems counts the UnprocessedItems of a BatchWriteItem and the
+// UnprocessedKeys of a BatchGetItem response by table.
+func unprocessedItems(response []byte) map[string]int {
+	var r struct {
+		UnprocessedItems map[string][]json.RawMessage
+		UnprocessedKeys  map[string]struct {
+			Keys []json.RawMessage
+		}
+	}
+	if json.Unmarshal(response, &r) != nil {
+		return nil
+	}
+
+	counts := map[string]int{}
+	for table, items := range r.UnprocessedItems {
+		counts[table] += len(items)
+	}
+	for table, keys := range r.UnprocessedKeys {
+		counts[table] += len(keys.Keys)
+	}
+	return counts
+}
@@ -0,0 +1,145 @@
+package ddbombprom_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/ryansb/dynamodbomb"
+	"github.com/ryansb/dynamodbomb/ddbombprom"
+	"github.com/ryansb/dynamodbomb/ddbombtest"
+	"launchpad.net/gocheck"
+)
+
+func Test(t *testing.T) {
+	gocheck.TestingT(t)
+}
+
+type MetricsSuite struct {
+	ts        *httptest.Server
+	server    *ddbomb.Server
+	metrics   *ddbombprom.Collector
+	throttles int               // GetItem requests throttled before reaching the fake
+	canned    map[string]string // responses by operation, instead of the fake's
+}
+
+var _ = gocheck.Suite(&MetricsSuite{})
+
+func (s *MetricsSuite) SetUpTest(c *gocheck.C) {
+	fake := ddbombtest.NewFake()
+	s.throttles = 0
+	s.canned = map[string]string{}
+	s.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+		if operation == "GetItem" && s.throttles > 0 {
+			s.throttles--
+			w.WriteHeader(400)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"slow down"}`))
+			return
+		}
+		if response, ok := s.canned[operation]; ok {
+			w.Write([]byte(response))
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+
+	s.server = ddbomb.NewLocalServer(s.ts.URL)
+	s.server.MaxRetries = 3
+	_, err := s.server.CreateTable(ddbomb.TableDescriptionT{
+		TableName:            "sites",
+		BillingMode:          ddbomb.BILLING_PAY_PER_REQUEST,
+		AttributeDefinitions: []ddbomb.AttributeDefinitionT{{Name: "Domain", Type: ddbomb.STRING}},
+		KeySchema:            []ddbomb.KeySchemaT{{AttributeName: "Domain", KeyType: ddbomb.HASH_KEY}},
+	})
+	c.Assert(err, gocheck.IsNil)
+
+	s.metrics = ddbombprom.NewCollector("test")
+	s.server.Use(s.metrics.Middleware())
+}
+
+func (s *MetricsSuite) TearDownTest(c *gocheck.C) {
+	s.ts.Close()
+}
+
+func (s *MetricsSuite) table() *ddbomb.Table {
+	return s.server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+}
+
+func (s *MetricsSuite) TestRequestsCapacityAndThrottles(c *gocheck.C) {
+	table := s.table()
+	_, err := table.PutItem("example.com", "", []ddbomb.Attribute{*ddbomb.NewStringAttribute("Owner", "ann")})
+	c.Assert(err, gocheck.IsNil)
+
+	s.throttles = 2
+	_, err = table.GetItemConsistent(&ddbomb.Key{HashKey: "example.com"}, true)
+	c.Assert(err, gocheck.IsNil)
+
+	c.Check(testutil.CollectAndCompare(s.metrics, strings.NewReader(`
+# HELP test_dynamodb_requests_total Operations sent to DynamoDB.
+# TYPE test_dynamodb_requests_total counter
+test_dynamodb_requests_total{operation="GetItem",table="sites"} 1
+test_dynamodb_requests_total{operation="PutItem",table="sites"} 1
+# HELP test_dynamodb_throttles_total Attempts rejected because of throttling.
+# TYPE test_dynamodb_throttles_total counter
+test_dynamodb_throttles_total{operation="GetItem",table="sites"} 2
+test_dynamodb_throttles_total{operation="PutItem",table="sites"} 0
+# HELP test_dynamodb_retries_total Attempts resent after a retryable error.
+# TYPE test_dynamodb_retries_total counter
+test_dynamodb_retries_total{operation="GetItem",table="sites"} 2
+test_dynamodb_retries_total{operation="PutItem",table="sites"} 0
+# HELP test_dynamodb_consumed_capacity_units_total Capacity units consumed, as reported by DynamoDB.
+# TYPE test_dynamodb_consumed_capacity_units_total counter
+test_dynamodb_consumed_capacity_units_total{operation="GetItem",table="sites"} 1
+test_dynamodb_consumed_capacity_units_total{operation="PutItem",table="sites"} 1
+`), "test_dynamodb_requests_total", "test_dynamodb_throttles_total", "test_dynamodb_retries_total",
+		"test_dynamodb_consumed_capacity_units_total"), gocheck.IsNil)
+
+	c.Check(testutil.CollectAndCount(s.metrics, "test_dynamodb_request_duration_seconds"), gocheck.Equals, 2)
+}
+
+func (s *MetricsSuite) TestOperationWithoutTable(c *gocheck.C) {
+	_, err := s.server.ListTables()
+	c.Assert(err, gocheck.IsNil)
+
+	c.Check(testutil.CollectAndCompare(s.metrics, strings.NewReader(`
+# HELP test_dynamodb_requests_total Operations sent to DynamoDB.
+# TYPE test_dynamodb_requests_total counter
+test_dynamodb_requests_total{operation="ListTables",table=""} 1
+`), "test_dynamodb_requests_total"), gocheck.IsNil)
+	c.Check(testutil.CollectAndCount(s.metrics, "test_dynamodb_request_duration_seconds"), gocheck.Equals, 1)
+}
+
+func (s *MetricsSuite) TestErrorsByCode(c *gocheck.C) {
+	_, err := s.server.DescribeTable("missing")
+	c.Assert(err, gocheck.NotNil)
+
+	c.Check(testutil.CollectAndCompare(s.metrics, strings.NewReader(`
+# HELP test_dynamodb_errors_total Operations that failed, by DynamoDB error code.
+# TYPE test_dynamodb_errors_total counter
+test_dynamodb_errors_total{code="ResourceNotFoundException",operation="DescribeTable",table="missing"} 1
+`), "test_dynamodb_errors_total"), gocheck.IsNil)
+}
+
+func (s *MetricsSuite) TestUnprocessedItems(c *gocheck.C) {
+	s.canned["BatchWriteItem"] = `{"UnprocessedItems": {"sites": [
+		{"PutRequest": {"Item": {"Domain": {"S": "a.com"}}}},
+		{"PutRequest": {"Item": {"Domain": {"S": "b.com"}}}}]}}`
+
+	batch := s.table().BatchWriteItems(map[string][][]ddbomb.Attribute{
+		"Put": {
+			{*ddbomb.NewStringAttribute("Domain", "a.com")},
+			{*ddbomb.NewStringAttribute("Domain", "b.com")},
+		},
+	})
+	_, err := batch.Execute()
+	c.Assert(err, gocheck.NotNil)
+
+	c.Check(testutil.CollectAndCompare(s.metrics, strings.NewReader(`
+# HELP test_dynamodb_unprocessed_items_total Items or keys left unprocessed by batch operations.
+# TYPE test_dynamodb_unprocessed_items_total counter
+test_dynamodb_unprocessed_items_total{operation="BatchWriteItem",table="sites"} 2
+`), "test_dynamodb_unprocessed_items_total"), gocheck.IsNil)
+}
@@ -0,0 +1,331 @@
+// Package ddbombtest provides test doubles for code built on ddbomb.
+//
+// Fake is an in-memory DynamoDB speaking the JSON wire protocol, so a real
+// ddbomb.Server can be pointed at it:
+//
+//	ts := httptest.NewServer(ddbombtest.NewFake())
+//	defer ts.Close()
+//	server := ddbomb.NewLocalServer(ts.URL)
+//
+// MockTable stands in for a ddbomb.TableAPI without any server at all.
+package ddbombtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+)
+
+const targetPrefix = "DynamoDB_20120810."
+
+// Fake is an http.Handler implementing the table and item operations of
+// DynamoDB in memory. Tables become ACTIVE as soon as they are created and
+// disappear as soon as they are deleted. Requests are not authenticated.
+type Fake struct {
+	mu     sync.Mutex
+	tables map[string]*fakeTable
+}
+
+// fakeError is answered to the client as a DynamoDB error response.
+type fakeError struct {
+	status int
+	code   string
+	msg    string
+}
+
+func (e *fakeError) Error() string {
+	return e.code + ": " + e.msg
+}
+
+func validationError(format string, args ...interface{}) *fakeError {
+	return &fakeError{400, "ValidationException", fmt.Sprintf(format, args...)}
+}
+
+func notFoundError() *fakeError {
+	return &fakeError{400, "ResourceNotFoundException", "Requested resource not found"}
+}
+
+func conditionalCheckFailed() *fakeError {
+	return &fakeError{400, "ConditionalCheckFailedException", "The conditional request failed"}
+}
+
+func NewFake() *Fake {
+	return &Fake{tables: map[string]*fakeTable{}}
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := r.Header.Get("X-Amz-Target")
+	if !strings.HasPrefix(target, targetPrefix) {
+		writeError(w, &fakeError{400, "UnknownOperationException", "Unknown target " + target})
+		return
+	}
+
+	var req request
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, &fakeError{400, "SerializationException", err.Error()})
+		return
+	}
+
+	name := strings.TrimPrefix(target, targetPrefix)
+	op, ok := operations[name]
+	if !ok {
+		writeError(w, &fakeError{400, "UnknownOperationException", "Unknown target " + target})
+		return
+	}
+
+	f.mu.Lock()
+	resp, err := op(f, req)
+	f.mu.Unlock()
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if rc := req.str("ReturnConsumedCapacity"); rc == "TOTAL" || rc == "INDEXES" {
+		addConsumedCapacity(name, req, resp)
+	}
+
+	body, jsonErr := json.Marshal(resp)
+	if jsonErr != nil {
+		writeError(w, &fakeError{500, "InternalServerError", jsonErr.Error()})
+		return
+	}
+	writeBody(w, 200, body)
+}
+
+func writeError(w http.ResponseWriter, err *fakeError) {
+	body, _ := json.Marshal(map[string]string{
+		"__type":  "com.amazonaws.dynamodb.v20120810#" + err.code,
+		"message": err.msg,
+	})
+	writeBody(w, err.status, body)
+}
+
+// writeBody writes a response along with its X-Amz-Crc32 checksum, as
+// DynamoDB does.
+func writeBody(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.Header().Set("X-Amz-Crc32", fmt.Sprint(crc32.ChecksumIEEE(body)))
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+type operation func(f *Fake, req request) (interface{}, *fakeError)
+
+var operations = map[string]operation{
+	"CreateTable":        (*Fake).createTable,
+	"DeleteTable":        (*Fake).deleteTable,
+	"DescribeTable":      (*Fake).describeTable,
+	"ListTables":         (*Fake).listTables,
+	"UpdateTable":        (*Fake).updateTable,
+	"DescribeTimeToLive": (*Fake).describeTimeToLive,
+	"UpdateTimeToLive":   (*Fake).updateTimeToLive,
+	"PutItem":            (*Fake).putItem,
+	"GetItem":            (*Fake).getItem,
+	"UpdateItem":         (*Fake).updateItem,
+	"DeleteItem":         (*Fake).deleteItem,
+	"BatchGetItem":       (*Fake).batchGetItem,
+	"BatchWriteItem":     (*Fake).batchWriteItem,
+	"Query":              (*Fake).query,
+	"Scan":               (*Fake).scan,
+}
+
+func (f *Fake) table(req request) (*fakeTable, *fakeError) {
+	t, ok := f.tables[req.str("TableName")]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return t, nil
+}
+
+func (f *Fake) createTable(req request) (interface{}, *fakeError) {
+	var desc ddbomb.TableDescriptionT
+	if err := req.decode(&desc); err != nil {
+		return nil, validationError("%s", err)
+	}
+	if desc.TableName == "" {
+		return nil, validationError("TableName is required")
+	}
+	if _, exists := f.tables[desc.TableName]; exists {
+		return nil, &fakeError{400, "ResourceInUseException", "Table already exists: " + desc.TableName}
+	}
+
+	t, err := newFakeTable(desc)
+	if err != nil {
+		return nil, err
+	}
+	f.tables[desc.TableName] = t
+	return msi{"TableDescription": t.describe()}, nil
+}
+
+func (f *Fake) deleteTable(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+	delete(f.tables, t.desc.TableName)
+
+	desc := t.describe()
+	desc.TableStatus = "DELETING"
+	return msi{"TableDescription": desc}, nil
+}
+
+func (f *Fake) describeTable(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+	return msi{"Table": t.describe()}, nil
+}
+
+func (f *Fake) listTables(req request) (interface{}, *fakeError) {
+	names := []string{}
+	for name := range f.tables {
+		if name > req.str("ExclusiveStartTableName") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	resp := msi{}
+	if limit := req.int("Limit"); limit > 0 && len(names) > limit {
+		names = names[:limit]
+		resp["LastEvaluatedTableName"] = names[limit-1]
+	}
+	resp["TableNames"] = names
+	return resp, nil
+}
+
+func (f *Fake) updateTable(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var update ddbomb.UpdateTableT
+	if err := req.decode(&update); err != nil {
+		return nil, validationError("%s", err)
+	}
+	if err := t.update(update); err != nil {
+		return nil, err
+	}
+	return msi{"TableDescription": t.describe()}, nil
+}
+
+func (f *Fake) describeTimeToLive(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+	return msi{"TimeToLiveDescription": t.ttl}, nil
+}
+
+func (f *Fake) updateTimeToLive(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := req.obj("TimeToLiveSpecification")
+	t.ttl.AttributeName = spec.str("AttributeName")
+	t.ttl.TimeToLiveStatus = "DISABLED"
+	if spec.bool("Enabled") {
+		t.ttl.TimeToLiveStatus = "ENABLED"
+	}
+	return msi{"TimeToLiveSpecification": spec}, nil
+}
+
+// addConsumedCapacity adds a rough ConsumedCapacity to the response of an
+// item operation: one unit per item written or read consistently, half a
+// unit per item read eventually consistently.
+func addConsumedCapacity(operation string, req request, resp interface{}) {
+	m, ok := resp.(msi)
+	if !ok {
+		return
+	}
+	readUnits := func(r request, items int) float64 {
+		if items < 1 {
+			items = 1
+		}
+		if r.bool("ConsistentRead") {
+			return float64(items)
+		}
+		return float64(items) / 2
+	}
+
+	switch operation {
+	case "PutItem", "UpdateItem", "DeleteItem":
+		m["ConsumedCapacity"] = ddbomb.ConsumedCapacityT{TableName: req.str("TableName"), CapacityUnits: 1}
+	case "GetItem":
+		m["ConsumedCapacity"] = ddbomb.ConsumedCapacityT{TableName: req.str("TableName"), CapacityUnits: readUnits(req, 1)}
+	case "Query", "Scan":
+		scanned, _ := m["ScannedCount"].(int)
+		m["ConsumedCapacity"] = ddbomb.ConsumedCapacityT{TableName: req.str("TableName"), CapacityUnits: readUnits(req, scanned)}
+	case "BatchGetItem", "BatchWriteItem":
+		var capacity []ddbomb.ConsumedCapacityT
+		requestItems := req.obj("RequestItems")
+		names := make([]string, 0, len(requestItems))
+		for tableName := range requestItems {
+			names = append(names, tableName)
+		}
+		sort.Strings(names)
+		for _, tableName := range names {
+			units := float64(len(requestItems.list(tableName)))
+			if operation == "BatchGetItem" {
+				tr := requestItems.obj(tableName)
+				units = readUnits(tr, len(tr.list("Keys")))
+			}
+			capacity = append(capacity, ddbomb.ConsumedCapacityT{TableName: tableName, CapacityUnits: units})
+		}
+		m["ConsumedCapacity"] = capacity
+	}
+}
+
+// newFakeTable checks the key schema of desc the way CreateTable would.
+func newFakeTable(desc ddbomb.TableDescriptionT) (*fakeTable, *fakeError) {
+	t := &fakeTable{
+		desc:      desc,
+		attrTypes: map[string]string{},
+		items:     map[string]item{},
+		ttl:       ddbomb.TimeToLiveDescriptionT{TimeToLiveStatus: "DISABLED"},
+	}
+	for _, ad := range desc.AttributeDefinitions {
+		t.attrTypes[ad.Name] = string(ad.Type)
+	}
+
+	var err *fakeError
+	if t.key, err = t.keySchema(desc.KeySchema); err != nil {
+		return nil, err
+	}
+	for i := range desc.GlobalSecondaryIndexes {
+		if err = t.addGlobalIndex(&desc.GlobalSecondaryIndexes[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, lsi := range desc.LocalSecondaryIndexes {
+		key, err := t.keySchema(lsi.KeySchema)
+		if err != nil {
+			return nil, err
+		}
+		t.indexes = append(t.indexes, &fakeIndex{lsi.IndexName, key, lsi.Projection})
+	}
+
+	t.desc.TableStatus = "ACTIVE"
+	t.desc.CreationDateTime = ddbomb.EpochTime{Time: time.Now()}
+	t.desc.TableArn = "arn:aws:dynamodb:ddblocal:000000000000:table/" + desc.TableName
+	t.desc.Tags = nil
+	if desc.BillingMode != "" {
+		t.desc.BillingModeSummary = &ddbomb.BillingModeSummaryT{BillingMode: desc.BillingMode}
+		t.desc.BillingMode = ""
+	}
+	return t, nil
+}
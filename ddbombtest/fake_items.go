@@ -0,0 +1,430 @@
+package ddbombtest
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+func (f *Fake) putItem(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+
+	it := req.item("Item")
+	key, err := t.primaryKey(it)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkExpected(t.items[key], req.obj("Expected")); err != nil {
+		return nil, err
+	}
+
+	t.items[key] = it
+	return msi{}, nil
+}
+
+func (f *Fake) getItem(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.keyOnly(req.item("Key"))
+	if err != nil {
+		return nil, err
+	}
+
+	it, ok := t.items[key]
+	if !ok {
+		return msi{}, nil
+	}
+	return msi{"Item": it.project(req.strings("AttributesToGet"))}, nil
+}
+
+func (f *Fake) deleteItem(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.keyOnly(req.item("Key"))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkExpected(t.items[key], req.obj("Expected")); err != nil {
+		return nil, err
+	}
+
+	delete(t.items, key)
+	return msi{}, nil
+}
+
+func (f *Fake) updateItem(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+
+	keyItem := req.item("Key")
+	key, err := t.keyOnly(keyItem)
+	if err != nil {
+		return nil, err
+	}
+
+	current := t.items[key]
+	if err := checkExpected(current, req.obj("Expected")); err != nil {
+		return nil, err
+	}
+
+	it := keyItem
+	if current != nil {
+		it = current.copy()
+	}
+
+	updates := req.obj("AttributeUpdates")
+	for name := range updates {
+		for _, k := range t.key.names {
+			if name == k {
+				return nil, validationError("Cannot update attribute %s. This attribute is part of the key", name)
+			}
+		}
+		if err := applyUpdate(it, name, updates.obj(name)); err != nil {
+			return nil, err
+		}
+	}
+
+	t.items[key] = it
+	return msi{}, nil
+}
+
+// applyUpdate performs one AttributeUpdates action on the item.
+func applyUpdate(it item, name string, update request) *fakeError {
+	v := toValue(update["Value"])
+	action := update.str("Action")
+	if action == "" {
+		action = "PUT"
+	}
+
+	switch action {
+	case "PUT":
+		if v == nil {
+			return validationError("Value is required for PUT on %s", name)
+		}
+		it[name] = v
+
+	case "DELETE":
+		if v == nil {
+			delete(it, name)
+			return nil
+		}
+		current, ok := it[name]
+		if !ok {
+			return nil
+		}
+		if !isSet(v.typ()) || current.typ() != v.typ() {
+			return validationError("Type mismatch for DELETE on %s", name)
+		}
+		var remaining []string
+		for _, m := range current.set() {
+			if !setContains(v.typ(), v.set(), m) {
+				remaining = append(remaining, m)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(it, name)
+		} else {
+			it[name] = newSet(v.typ(), remaining)
+		}
+
+	case "ADD":
+		if v == nil {
+			return validationError("Value is required for ADD on %s", name)
+		}
+		current, ok := it[name]
+		switch {
+		case !ok:
+			it[name] = v
+		case current.typ() != v.typ():
+			return validationError("Type mismatch for ADD on %s", name)
+		case v.typ() == "N":
+			sum := parseNumber(current.scalar())
+			sum.Add(sum, parseNumber(v.scalar()))
+			it[name] = value{"N": formatNumber(sum)}
+		case isSet(v.typ()):
+			members := current.set()
+			for _, m := range v.set() {
+				if !setContains(v.typ(), members, m) {
+					members = append(members, m)
+				}
+			}
+			it[name] = newSet(v.typ(), members)
+		default:
+			return validationError("ADD is only supported on numbers and sets, not %s", name)
+		}
+
+	default:
+		return validationError("Unknown action %s", action)
+	}
+	return nil
+}
+
+func (f *Fake) batchGetItem(req request) (interface{}, *fakeError) {
+	responses := msi{}
+	requestItems := req.obj("RequestItems")
+	for tableName := range requestItems {
+		t, ok := f.tables[tableName]
+		if !ok {
+			return nil, notFoundError()
+		}
+		tr := requestItems.obj(tableName)
+
+		found := []item{}
+		for _, rawKey := range tr.list("Keys") {
+			key, err := t.keyOnly(toItem(rawKey))
+			if err != nil {
+				return nil, err
+			}
+			if it, ok := t.items[key]; ok {
+				found = append(found, it.project(tr.strings("AttributesToGet")))
+			}
+		}
+		responses[tableName] = found
+	}
+	return msi{"Responses": responses, "UnprocessedKeys": msi{}}, nil
+}
+
+func (f *Fake) batchWriteItem(req request) (interface{}, *fakeError) {
+	requestItems := req.obj("RequestItems")
+
+	// Validate everything first; a batch is rejected as a whole.
+	type write struct {
+		t   *fakeTable
+		key string
+		it  item // nil for a delete
+	}
+	var writes []write
+	for tableName := range requestItems {
+		t, ok := f.tables[tableName]
+		if !ok {
+			return nil, notFoundError()
+		}
+		for _, raw := range requestItems.list(tableName) {
+			wr := request(toRequest(raw))
+			switch {
+			case wr.has("PutRequest"):
+				it := wr.obj("PutRequest").item("Item")
+				key, err := t.primaryKey(it)
+				if err != nil {
+					return nil, err
+				}
+				writes = append(writes, write{t, key, it})
+			case wr.has("DeleteRequest"):
+				key, err := t.keyOnly(wr.obj("DeleteRequest").item("Key"))
+				if err != nil {
+					return nil, err
+				}
+				writes = append(writes, write{t, key, nil})
+			default:
+				return nil, validationError("Each write request must be a PutRequest or a DeleteRequest")
+			}
+		}
+	}
+
+	for _, w := range writes {
+		if w.it == nil {
+			delete(w.t.items, w.key)
+		} else {
+			w.t.items[w.key] = w.it
+		}
+	}
+	return msi{"UnprocessedItems": msi{}}, nil
+}
+
+func toRequest(raw interface{}) map[string]interface{} {
+	m, _ := raw.(map[string]interface{})
+	return m
+}
+
+func (f *Fake) query(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.read(req, true)
+}
+
+func (f *Fake) scan(req request) (interface{}, *fakeError) {
+	t, err := f.table(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.read(req, false)
+}
+
+// candidates returns the items visible through the index, or the whole
+// table when ind is nil, in key order.
+func (t *fakeTable) candidates(ind *fakeIndex) []item {
+	order := t.key.names
+	if ind != nil {
+		order = append(append([]string(nil), ind.key.names...), t.key.names...)
+	}
+
+	var items []item
+	for _, it := range t.items {
+		visible := true
+		for _, name := range order {
+			if _, ok := it[name]; !ok {
+				visible = false
+			}
+		}
+		if visible {
+			items = append(items, it)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return compareItems(items[i], items[j], order) < 0
+	})
+	return items
+}
+
+func compareItems(a, b item, order []string) int {
+	for _, name := range order {
+		cmp, _ := compareValues(a[name], b[name])
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// read implements Query (keyed) and Scan over the table or one of its
+// indexes, honoring filters, Limit, ExclusiveStartKey, Select and segments.
+func (t *fakeTable) read(req request, keyed bool) (interface{}, *fakeError) {
+	var ind *fakeIndex
+	key := t.key
+	if name := req.str("IndexName"); name != "" {
+		if ind = t.index(name); ind == nil {
+			return nil, validationError("The table does not have the specified index: %s", name)
+		}
+		key = ind.key
+	}
+
+	order := t.key.names
+	if ind != nil {
+		order = append(append([]string(nil), ind.key.names...), t.key.names...)
+	}
+	items := t.candidates(ind)
+
+	filterName := "ScanFilter"
+	forward := true
+	if keyed {
+		filterName = "QueryFilter"
+		forward = !req.has("ScanIndexForward") || req.bool("ScanIndexForward")
+
+		conditions := req.obj("KeyConditions")
+		if _, ok := conditions[key.hash]; !ok {
+			return nil, validationError("Query condition missed key schema element: %s", key.hash)
+		}
+		for name := range conditions {
+			if name != key.hash && name != key.rng {
+				return nil, validationError("Query condition references non-key attribute %s", name)
+			}
+		}
+		if conditions.obj(key.hash).str("ComparisonOperator") != "EQ" {
+			return nil, validationError("Query key condition not supported")
+		}
+
+		var matched []item
+		for _, it := range items {
+			ok, err := filter(it, conditions)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, it)
+			}
+		}
+		items = matched
+	}
+
+	if total := req.int("TotalSegments"); total > 0 {
+		segment := uint32(req.int("Segment"))
+		var matched []item
+		for _, it := range items {
+			pk, _ := t.primaryKey(it)
+			h := fnv.New32a()
+			h.Write([]byte(pk))
+			if h.Sum32()%uint32(total) == segment {
+				matched = append(matched, it)
+			}
+		}
+		items = matched
+	}
+
+	if !forward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	if start := req.item("ExclusiveStartKey"); start != nil {
+		pos := len(items)
+		for i, it := range items {
+			cmp := compareItems(it, start, order)
+			if forward && cmp > 0 || !forward && cmp < 0 {
+				pos = i
+				break
+			}
+		}
+		items = items[pos:]
+	}
+
+	limit := req.int("Limit")
+	conditions := req.obj(filterName)
+	projection := ind.projected(t.key)
+	if names := req.strings("AttributesToGet"); len(names) > 0 {
+		projection = names
+	}
+
+	found := []item{}
+	scanned := 0
+	var last item
+	for _, it := range items {
+		if limit > 0 && scanned == limit {
+			break
+		}
+		scanned++
+		last = it
+
+		ok, err := filter(it, conditions)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			found = append(found, it.project(projection))
+		}
+	}
+
+	resp := msi{"Count": len(found), "ScannedCount": scanned}
+	if req.str("Select") != "COUNT" {
+		resp["Items"] = found
+	}
+	if limit > 0 && scanned == limit && scanned < len(items) {
+		resp["LastEvaluatedKey"] = last.project(order)
+	}
+	return resp, nil
+}
+
+// projected lists the attributes an index returns, nil meaning all of them.
+func (ind *fakeIndex) projected(tableKey keyDef) []string {
+	if ind == nil || ind.projection.ProjectionType == "" || ind.projection.ProjectionType == "ALL" {
+		return nil
+	}
+	names := append(append([]string(nil), tableKey.names...), ind.key.names...)
+	if ind.projection.ProjectionType == "INCLUDE" {
+		names = append(names, ind.projection.NonKeyAttributes...)
+	}
+	return names
+}
@@ -0,0 +1,267 @@
+package ddbombtest
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/ryansb/dynamodbomb"
+)
+
+type msi map[string]interface{}
+
+// request is a decoded request body. Numbers are kept as json.Number.
+type request map[string]interface{}
+
+func (r request) str(name string) string {
+	s, _ := r[name].(string)
+	return s
+}
+
+func (r request) int(name string) int {
+	switch v := r[name].(type) {
+	case json.Number:
+		n, _ := v.Int64()
+		return int(n)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
+
+// bool accepts both JSON booleans and the "true" strings ddbomb sends for
+// some flags.
+func (r request) bool(name string) bool {
+	switch v := r[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+func (r request) has(name string) bool {
+	_, ok := r[name]
+	return ok
+}
+
+func (r request) obj(name string) request {
+	m, _ := r[name].(map[string]interface{})
+	return request(m)
+}
+
+func (r request) list(name string) []interface{} {
+	l, _ := r[name].([]interface{})
+	return l
+}
+
+func (r request) strings(name string) []string {
+	var out []string
+	for _, v := range r.list(name) {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// item reads an attribute map such as Item, Key or ExclusiveStartKey.
+func (r request) item(name string) item {
+	return toItem(r[name])
+}
+
+// decode fills v, a ddbomb description type, from the request.
+func (r request) decode(v interface{}) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+type keyDef struct {
+	hash  string
+	rng   string // empty when the key has no range attribute
+	names []string
+}
+
+type fakeIndex struct {
+	name       string
+	key        keyDef
+	projection ddbomb.ProjectionT
+}
+
+type fakeTable struct {
+	desc      ddbomb.TableDescriptionT
+	attrTypes map[string]string
+	key       keyDef
+	indexes   []*fakeIndex
+	items     map[string]item
+	ttl       ddbomb.TimeToLiveDescriptionT
+}
+
+func (t *fakeTable) keySchema(schema []ddbomb.KeySchemaT) (keyDef, *fakeError) {
+	var k keyDef
+	for _, ks := range schema {
+		if _, ok := t.attrTypes[ks.AttributeName]; !ok {
+			return k, validationError("Attribute %s is not defined in AttributeDefinitions", ks.AttributeName)
+		}
+		switch ks.KeyType {
+		case ddbomb.HASH_KEY:
+			k.hash = ks.AttributeName
+		case ddbomb.RANGE_KEY:
+			k.rng = ks.AttributeName
+		}
+	}
+	if k.hash == "" {
+		return k, validationError("KeySchema must contain a HASH key")
+	}
+	k.names = []string{k.hash}
+	if k.rng != "" {
+		k.names = append(k.names, k.rng)
+	}
+	return k, nil
+}
+
+func (t *fakeTable) addGlobalIndex(gsi *ddbomb.GlobalSecondaryIndexT) *fakeError {
+	key, err := t.keySchema(gsi.KeySchema)
+	if err != nil {
+		return err
+	}
+	if t.index(gsi.IndexName) != nil {
+		return validationError("Index %s already exists", gsi.IndexName)
+	}
+	t.indexes = append(t.indexes, &fakeIndex{gsi.IndexName, key, gsi.Projection})
+	gsi.IndexStatus = "ACTIVE"
+	gsi.IndexArn = "arn:aws:dynamodb:ddblocal:000000000000:table/" + t.desc.TableName + "/index/" + gsi.IndexName
+	return nil
+}
+
+func (t *fakeTable) index(name string) *fakeIndex {
+	for _, ind := range t.indexes {
+		if ind.name == name {
+			return ind
+		}
+	}
+	return nil
+}
+
+// describe returns the table description with up to date item counts.
+func (t *fakeTable) describe() ddbomb.TableDescriptionT {
+	desc := t.desc
+	desc.ItemCount = int64(len(t.items))
+	desc.TableSizeBytes = 0
+	for _, it := range t.items {
+		body, _ := json.Marshal(it)
+		desc.TableSizeBytes += int64(len(body))
+	}
+
+	desc.GlobalSecondaryIndexes = append([]ddbomb.GlobalSecondaryIndexT(nil), t.desc.GlobalSecondaryIndexes...)
+	for i := range desc.GlobalSecondaryIndexes {
+		desc.GlobalSecondaryIndexes[i].ItemCount = int64(len(t.candidates(t.index(desc.GlobalSecondaryIndexes[i].IndexName))))
+	}
+	return desc
+}
+
+func (t *fakeTable) update(u ddbomb.UpdateTableT) *fakeError {
+	for _, ad := range u.AttributeDefinitions {
+		t.attrTypes[ad.Name] = string(ad.Type)
+		if findAttributeDefinition(t.desc.AttributeDefinitions, ad.Name) < 0 {
+			t.desc.AttributeDefinitions = append(t.desc.AttributeDefinitions, ad)
+		}
+	}
+
+	if u.BillingMode != "" {
+		t.desc.BillingModeSummary = &ddbomb.BillingModeSummaryT{BillingMode: u.BillingMode}
+	}
+	if u.ProvisionedThroughput != nil {
+		t.desc.ProvisionedThroughput.ReadCapacityUnits = u.ProvisionedThroughput.ReadCapacityUnits
+		t.desc.ProvisionedThroughput.WriteCapacityUnits = u.ProvisionedThroughput.WriteCapacityUnits
+	}
+
+	for _, gu := range u.GlobalSecondaryIndexUpdates {
+		switch {
+		case gu.Create != nil:
+			gsi := *gu.Create
+			if err := t.addGlobalIndex(&gsi); err != nil {
+				return err
+			}
+			t.desc.GlobalSecondaryIndexes = append(t.desc.GlobalSecondaryIndexes, gsi)
+		case gu.Update != nil:
+			i := t.globalIndexPosition(gu.Update.IndexName)
+			if i < 0 {
+				return notFoundError()
+			}
+			t.desc.GlobalSecondaryIndexes[i].ProvisionedThroughput = gu.Update.ProvisionedThroughput
+		case gu.Delete != nil:
+			i := t.globalIndexPosition(gu.Delete.IndexName)
+			if i < 0 {
+				return notFoundError()
+			}
+			gsis := t.desc.GlobalSecondaryIndexes
+			t.desc.GlobalSecondaryIndexes = append(gsis[:i:i], gsis[i+1:]...)
+			for j, ind := range t.indexes {
+				if ind.name == gu.Delete.IndexName {
+					t.indexes = append(t.indexes[:j:j], t.indexes[j+1:]...)
+					break
+				}
+			}
+		}
+	}
+
+	if u.StreamSpecification != nil {
+		spec := *u.StreamSpecification
+		t.desc.StreamSpecification = &spec
+		if spec.StreamEnabled {
+			t.desc.LatestStreamLabel = "2000-01-01T00:00:00.000"
+			t.desc.LatestStreamArn = t.desc.TableArn + "/stream/" + t.desc.LatestStreamLabel
+		}
+	}
+	return nil
+}
+
+func (t *fakeTable) globalIndexPosition(name string) int {
+	for i, gsi := range t.desc.GlobalSecondaryIndexes {
+		if gsi.IndexName == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func findAttributeDefinition(ads []ddbomb.AttributeDefinitionT, name string) int {
+	for i, ad := range ads {
+		if ad.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// primaryKey checks that it carries the table key with the defined types
+// and returns the string the item is stored under.
+func (t *fakeTable) primaryKey(it item) (string, *fakeError) {
+	var parts []value
+	for _, name := range t.key.names {
+		v, ok := it[name]
+		if !ok {
+			return "", validationError("One or more parameter values were invalid: Missing the key %s in the item", name)
+		}
+		if v.typ() != t.attrTypes[name] {
+			return "", validationError("One or more parameter values were invalid: Type mismatch for key %s", name)
+		}
+		parts = append(parts, v)
+	}
+	body, _ := json.Marshal(parts)
+	return string(body), nil
+}
+
+// keyOnly reduces a Key parameter to the table key, rejecting extra
+// attributes like DynamoDB does.
+func (t *fakeTable) keyOnly(key item) (string, *fakeError) {
+	if len(key) != len(t.key.names) {
+		return "", validationError("The provided key element does not match the schema")
+	}
+	return t.primaryKey(key)
+}
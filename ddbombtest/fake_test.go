@@ -0,0 +1,105 @@
+package ddbombtest_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ryansb/dynamodbomb"
+	"github.com/ryansb/dynamodbomb/ddbombtest"
+	"launchpad.net/gocheck"
+)
+
+func Test(t *testing.T) {
+	gocheck.TestingT(t)
+}
+
+type FakeSuite struct {
+	ts    *httptest.Server
+	table *ddbomb.Table
+}
+
+var _ = gocheck.Suite(&FakeSuite{})
+
+func (s *FakeSuite) SetUpTest(c *gocheck.C) {
+	s.ts = httptest.NewServer(ddbombtest.NewFake())
+	server := ddbomb.NewLocalServer(s.ts.URL)
+
+	desc := ddbomb.TableDescriptionT{
+		TableName:   "Orders",
+		BillingMode: ddbomb.BILLING_PAY_PER_REQUEST,
+		AttributeDefinitions: []ddbomb.AttributeDefinitionT{
+			{Name: "Id", Type: ddbomb.STRING},
+			{Name: "Customer", Type: ddbomb.STRING},
+			{Name: "Placed", Type: ddbomb.NUMBER},
+		},
+		KeySchema: []ddbomb.KeySchemaT{{AttributeName: "Id", KeyType: ddbomb.HASH_KEY}},
+		GlobalSecondaryIndexes: []ddbomb.GlobalSecondaryIndexT{{
+			IndexName: "ByCustomer",
+			KeySchema: []ddbomb.KeySchemaT{
+				{AttributeName: "Customer", KeyType: ddbomb.HASH_KEY},
+				{AttributeName: "Placed", KeyType: ddbomb.RANGE_KEY},
+			},
+			Projection: ddbomb.ProjectionT{ProjectionType: ddbomb.PROJ_KEYS_ONLY},
+		}},
+	}
+	if _, err := server.CreateTable(desc); err != nil {
+		c.Fatal(err)
+	}
+	pk, err := desc.BuildPrimaryKey()
+	if err != nil {
+		c.Fatal(err)
+	}
+	s.table = server.NewTable(desc.TableName, pk)
+}
+
+func (s *FakeSuite) TearDownTest(c *gocheck.C) {
+	s.ts.Close()
+}
+
+func (s *FakeSuite) put(c *gocheck.C, id, customer, placed string) {
+	attrs := []ddbomb.Attribute{
+		*ddbomb.NewStringAttribute("Customer", customer),
+		*ddbomb.NewNumericAttribute("Placed", placed),
+		*ddbomb.NewStringAttribute("Note", "not projected"),
+	}
+	if _, err := s.table.PutItem(id, "", attrs); err != nil {
+		c.Fatal(err)
+	}
+}
+
+func (s *FakeSuite) TestQueryIndexInKeyOrder(c *gocheck.C) {
+	s.put(c, "a", "bob", "30")
+	s.put(c, "b", "bob", "4")
+	s.put(c, "c", "alice", "10")
+
+	items, err := s.table.QueryOnIndex("ByCustomer",
+		*ddbomb.NewEqualStringAttributeComparison("Customer", "bob"))
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(items, gocheck.HasLen, 2)
+
+	c.Check(items[0]["Id"].Value, gocheck.Equals, "b")
+	c.Check(items[1]["Id"].Value, gocheck.Equals, "a")
+	_, projected := items[0]["Note"]
+	c.Check(projected, gocheck.Equals, false)
+}
+
+func (s *FakeSuite) TestConditionalPutFails(c *gocheck.C) {
+	s.put(c, "a", "bob", "1")
+
+	expected := []ddbomb.Attribute{*ddbomb.NewStringAttribute("Customer", "alice")}
+	ok, err := s.table.ConditionalPutItem("a", "", []ddbomb.Attribute{
+		*ddbomb.NewStringAttribute("Customer", "carol"),
+	}, expected)
+	c.Check(ok, gocheck.Equals, false)
+	c.Check(err, gocheck.ErrorMatches, "ConditionalCheckFailedException.*")
+}
+
+func (s *FakeSuite) TestScanPagesWithLimit(c *gocheck.C) {
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		s.put(c, id, "bob", "1")
+	}
+
+	count, err := s.table.CountScanWithOptions(&ddbomb.ScanOptions{Limit: 2})
+	c.Assert(err, gocheck.IsNil)
+	c.Check(count, gocheck.Equals, int64(5))
+}
@@ -0,0 +1,320 @@
+package ddbombtest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// value is an attribute value in wire format, such as {"S": "foo"}.
+type value map[string]interface{}
+
+// item is an attribute map in wire format.
+type item map[string]value
+
+func toValue(raw interface{}) value {
+	m, _ := raw.(map[string]interface{})
+	if len(m) == 0 {
+		return nil
+	}
+	return value(m)
+}
+
+func toValues(raw []interface{}) []value {
+	values := make([]value, len(raw))
+	for i, r := range raw {
+		values[i] = toValue(r)
+	}
+	return values
+}
+
+func toItem(raw interface{}) item {
+	m, _ := raw.(map[string]interface{})
+	if m == nil {
+		return nil
+	}
+	it := item{}
+	for name, v := range m {
+		if av := toValue(v); av != nil {
+			it[name] = av
+		}
+	}
+	return it
+}
+
+func (it item) copy() item {
+	out := item{}
+	for name, v := range it {
+		out[name] = v
+	}
+	return out
+}
+
+// project keeps only the named attributes, or everything when names is empty.
+func (it item) project(names []string) item {
+	if len(names) == 0 {
+		return it
+	}
+	out := item{}
+	for _, name := range names {
+		if v, ok := it[name]; ok {
+			out[name] = v
+		}
+	}
+	return out
+}
+
+func (v value) typ() string {
+	for t := range v {
+		return t
+	}
+	return ""
+}
+
+func (v value) scalar() string {
+	s, _ := v[v.typ()].(string)
+	return s
+}
+
+func (v value) set() []string {
+	raw, _ := v[v.typ()].([]interface{})
+	out := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if s, ok := r.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func isSet(typ string) bool {
+	return typ == "SS" || typ == "NS" || typ == "BS"
+}
+
+// elementType is the scalar type of the members of a set type.
+func elementType(setType string) string {
+	return setType[:1]
+}
+
+func newSet(typ string, members []string) value {
+	raw := make([]interface{}, len(members))
+	for i, m := range members {
+		raw[i] = m
+	}
+	return value{typ: raw}
+}
+
+func parseNumber(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+func formatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	s := strings.TrimRight(r.FloatString(38), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// compareScalars orders two scalars of the same type. ok is false when they
+// can't be compared.
+func compareScalars(typ, a, b string) (cmp int, ok bool) {
+	switch typ {
+	case "S":
+		return strings.Compare(a, b), true
+	case "N":
+		return parseNumber(a).Cmp(parseNumber(b)), true
+	case "B":
+		ab, _ := base64.StdEncoding.DecodeString(a)
+		bb, _ := base64.StdEncoding.DecodeString(b)
+		return bytes.Compare(ab, bb), true
+	}
+	return 0, false
+}
+
+func compareValues(a, b value) (int, bool) {
+	if a.typ() != b.typ() {
+		return 0, false
+	}
+	return compareScalars(a.typ(), a.scalar(), b.scalar())
+}
+
+func setContains(typ string, members []string, m string) bool {
+	for _, member := range members {
+		if cmp, ok := compareScalars(elementType(typ), member, m); ok && cmp == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func equalValues(a, b value) bool {
+	if a.typ() != b.typ() {
+		return false
+	}
+	if isSet(a.typ()) {
+		as, bs := a.set(), b.set()
+		if len(as) != len(bs) {
+			return false
+		}
+		for _, m := range as {
+			if !setContains(a.typ(), bs, m) {
+				return false
+			}
+		}
+		return true
+	}
+	if cmp, ok := compareValues(a, b); ok {
+		return cmp == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// matches evaluates a legacy ComparisonOperator condition against the
+// attribute, which is nil when the item doesn't have it.
+func matches(attr value, op string, args []value) (bool, *fakeError) {
+	arg := func(i int) (value, *fakeError) {
+		if i >= len(args) || args[i] == nil {
+			return nil, validationError("Invalid number of argument(s) for the %s ComparisonOperator", op)
+		}
+		return args[i], nil
+	}
+	ordered := func(want func(int) bool) (bool, *fakeError) {
+		a, err := arg(0)
+		if err != nil || attr == nil {
+			return false, err
+		}
+		cmp, ok := compareValues(attr, a)
+		return ok && want(cmp), nil
+	}
+
+	switch op {
+	case "EQ":
+		a, err := arg(0)
+		return attr != nil && err == nil && equalValues(attr, a), err
+	case "NE":
+		a, err := arg(0)
+		return attr == nil || err == nil && !equalValues(attr, a), err
+	case "LT":
+		return ordered(func(c int) bool { return c < 0 })
+	case "LE":
+		return ordered(func(c int) bool { return c <= 0 })
+	case "GT":
+		return ordered(func(c int) bool { return c > 0 })
+	case "GE":
+		return ordered(func(c int) bool { return c >= 0 })
+	case "NOT_NULL":
+		return attr != nil, nil
+	case "NULL":
+		return attr == nil, nil
+	case "CONTAINS", "NOT_CONTAINS":
+		a, err := arg(0)
+		if err != nil || attr == nil {
+			return false, err
+		}
+		return contains(attr, a) == (op == "CONTAINS"), nil
+	case "BEGINS_WITH":
+		a, err := arg(0)
+		if err != nil || attr == nil || attr.typ() != a.typ() {
+			return false, err
+		}
+		switch a.typ() {
+		case "S":
+			return strings.HasPrefix(attr.scalar(), a.scalar()), nil
+		case "B":
+			ab, _ := base64.StdEncoding.DecodeString(attr.scalar())
+			prefix, _ := base64.StdEncoding.DecodeString(a.scalar())
+			return bytes.HasPrefix(ab, prefix), nil
+		}
+		return false, validationError("BEGINS_WITH requires a string or binary argument")
+	case "IN":
+		if attr == nil {
+			return false, nil
+		}
+		for _, a := range args {
+			if equalValues(attr, a) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "BETWEEN":
+		low, err := arg(0)
+		if err != nil {
+			return false, err
+		}
+		high, err := arg(1)
+		if err != nil || attr == nil {
+			return false, err
+		}
+		lowCmp, ok1 := compareValues(attr, low)
+		highCmp, ok2 := compareValues(attr, high)
+		return ok1 && ok2 && lowCmp >= 0 && highCmp <= 0, nil
+	}
+	return false, validationError("Unsupported ComparisonOperator %s", op)
+}
+
+func contains(attr, a value) bool {
+	switch {
+	case isSet(attr.typ()):
+		return elementType(attr.typ()) == a.typ() && setContains(attr.typ(), attr.set(), a.scalar())
+	case attr.typ() == "S" && a.typ() == "S":
+		return strings.Contains(attr.scalar(), a.scalar())
+	case attr.typ() == "B" && a.typ() == "B":
+		ab, _ := base64.StdEncoding.DecodeString(attr.scalar())
+		sub, _ := base64.StdEncoding.DecodeString(a.scalar())
+		return bytes.Contains(ab, sub)
+	}
+	return false
+}
+
+// filter reports whether the item satisfies every condition of a
+// ScanFilter, QueryFilter or KeyConditions map.
+func filter(it item, conditions request) (bool, *fakeError) {
+	for name := range conditions {
+		c := conditions.obj(name)
+		ok, err := matches(it[name], c.str("ComparisonOperator"), toValues(c.list("AttributeValueList")))
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkExpected evaluates the legacy Expected parameter against the current
+// item, which is nil when there is none.
+func checkExpected(current item, expected request) *fakeError {
+	for name := range expected {
+		e := expected.obj(name)
+		attr := current[name]
+
+		var ok bool
+		switch {
+		case e.has("ComparisonOperator"):
+			var err *fakeError
+			ok, err = matches(attr, e.str("ComparisonOperator"), toValues(e.list("AttributeValueList")))
+			if err != nil {
+				return err
+			}
+		case e.has("Exists") && !e.bool("Exists"):
+			if e.has("Value") {
+				return validationError("Value cannot be used when Exists is false for %s", name)
+			}
+			ok = attr == nil
+		case e.has("Value"):
+			ok = attr != nil && equalValues(attr, toValue(e["Value"]))
+		default:
+			return validationError("Value must be provided when Exists is true for %s", name)
+		}
+
+		if !ok {
+			return conditionalCheckFailed()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package ddbombtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+)
+
+// Call is one call recorded by a MockTable. Variadic arguments are recorded
+// as a single slice.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// MockTable is a ddbomb.TableAPI that records every call made on it and
+// answers with the results set up by On. A method without results returns
+// zero values and a nil error, and a write then reports success.
+//
+// The zero value is ready to use, and a MockTable is safe for concurrent use.
+type MockTable struct {
+	mu      sync.Mutex
+	calls   []Call
+	results map[string][]results
+}
+
+var _ ddbomb.TableAPI = (*MockTable)(nil)
+
+var tableAPIType = reflect.TypeOf((*ddbomb.TableAPI)(nil)).Elem()
+
+// On queues the values returned by the next call to method, given in the
+// order of the method's results; a nil stands for the zero value. Once the
+// queue runs out its last entry is repeated. On panics if method isn't part
+// of ddbomb.TableAPI.
+func (m *MockTable) On(method string, values ...interface{}) *MockTable {
+	if _, ok := tableAPIType.MethodByName(method); !ok {
+		panic("ddbombtest: " + method + " is not a method of ddbomb.TableAPI")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.results == nil {
+		m.results = map[string][]results{}
+	}
+	m.results[method] = append(m.results[method], results{method, values})
+	return m
+}
+
+// Calls returns every call made so far, oldest first.
+func (m *MockTable) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made so far to method, oldest first.
+func (m *MockTable) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (m *MockTable) called(method string, args ...interface{}) results {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{method, args})
+
+	queue := m.results[method]
+	if len(queue) == 0 {
+		return results{method: method}
+	}
+	if len(queue) > 1 {
+		m.results[method] = queue[1:]
+	}
+	return queue[0]
+}
+
+type results struct {
+	method string
+	values []interface{}
+}
+
+// into stores the queued values in the method's result variables.
+func (r results) into(dst ...interface{}) {
+	for i, d := range dst {
+		if i >= len(r.values) || r.values[i] == nil {
+			continue
+		}
+		target := reflect.ValueOf(d).Elem()
+		v := reflect.ValueOf(r.values[i])
+		if !v.Type().AssignableTo(target.Type()) {
+			panic(fmt.Sprintf("ddbombtest: result %d of %s must be a %s, not a %s", i, r.method, target.Type(), v.Type()))
+		}
+		target.Set(v)
+	}
+}
+
+func (m *MockTable) write(method string, args ...interface{}) (ok bool, err error) {
+	r := m.called(method, args...)
+	r.into(&ok, &err)
+	if len(r.values) == 0 || r.values[0] == nil {
+		ok = err == nil
+	}
+	return
+}
+
+func (m *MockTable) GetItem(key *ddbomb.Key) (item map[string]ddbomb.Attribute, err error) {
+	m.called("GetItem", key).into(&item, &err)
+	return
+}
+
+func (m *MockTable) GetItemConsistent(key *ddbomb.Key, consistentRead bool) (item map[string]ddbomb.Attribute, err error) {
+	m.called("GetItemConsistent", key, consistentRead).into(&item, &err)
+	return
+}
+
+func (m *MockTable) PutItem(hashKey string, rangeKey string, attributes []ddbomb.Attribute) (bool, error) {
+	return m.write("PutItem", hashKey, rangeKey, attributes)
+}
+
+func (m *MockTable) ConditionalPutItem(hashKey, rangeKey string, attributes, expected []ddbomb.Attribute) (bool, error) {
+	return m.write("ConditionalPutItem", hashKey, rangeKey, attributes, expected)
+}
+
+func (m *MockTable) DeleteItem(key *ddbomb.Key) (bool, error) {
+	return m.write("DeleteItem", key)
+}
+
+func (m *MockTable) ConditionalDeleteItem(key *ddbomb.Key, expected []ddbomb.Attribute) (bool, error) {
+	return m.write("ConditionalDeleteItem", key, expected)
+}
+
+func (m *MockTable) AddAttributes(key *ddbomb.Key, attributes []ddbomb.Attribute) (bool, error) {
+	return m.write("AddAttributes", key, attributes)
+}
+
+func (m *MockTable) UpdateAttributes(key *ddbomb.Key, attributes []ddbomb.Attribute) (bool, error) {
+	return m.write("UpdateAttributes", key, attributes)
+}
+
+func (m *MockTable) DeleteAttributes(key *ddbomb.Key, attributes []ddbomb.Attribute) (bool, error) {
+	return m.write("DeleteAttributes", key, attributes)
+}
+
+func (m *MockTable) ConditionalAddAttributes(key *ddbomb.Key, attributes, expected []ddbomb.Attribute) (bool, error) {
+	return m.write("ConditionalAddAttributes", key, attributes, expected)
+}
+
+func (m *MockTable) ConditionalUpdateAttributes(key *ddbomb.Key, attributes, expected []ddbomb.Attribute) (bool, error) {
+	return m.write("ConditionalUpdateAttributes", key, attributes, expected)
+}
+
+func (m *MockTable) ConditionalDeleteAttributes(key *ddbomb.Key, attributes, expected []ddbomb.Attribute) (bool, error) {
+	return m.write("ConditionalDeleteAttributes", key, attributes, expected)
+}
+
+func (m *MockTable) Query(attributeComparisons ...ddbomb.AttributeComparison) (items []map[string]ddbomb.Attribute, err error) {
+	m.called("Query", attributeComparisons).into(&items, &err)
+	return
+}
+
+func (m *MockTable) QueryOnIndex(indexName string, attributeComparisons ...ddbomb.AttributeComparison) (items []map[string]ddbomb.Attribute, err error) {
+	m.called("QueryOnIndex", indexName, attributeComparisons).into(&items, &err)
+	return
+}
+
+func (m *MockTable) LimitedQuery(attributeComparisons []ddbomb.AttributeComparison, limit int64) (items []map[string]ddbomb.Attribute, err error) {
+	m.called("LimitedQuery", attributeComparisons, limit).into(&items, &err)
+	return
+}
+
+func (m *MockTable) LimitedQueryOnIndex(attributeComparisons []ddbomb.AttributeComparison, indexName string, limit int64) (items []map[string]ddbomb.Attribute, err error) {
+	m.called("LimitedQueryOnIndex", attributeComparisons, indexName, limit).into(&items, &err)
+	return
+}
+
+func (m *MockTable) CountQuery(attributeComparisons []ddbomb.AttributeComparison) (count int64, err error) {
+	m.called("CountQuery", attributeComparisons).into(&count, &err)
+	return
+}
+
+func (m *MockTable) FetchResults(query *ddbomb.Query) (items []map[string]ddbomb.Attribute, err error) {
+	m.called("FetchResults", query).into(&items, &err)
+	return
+}
+
+func (m *MockTable) Scan(attributeComparisons ...ddbomb.AttributeComparison) (items []map[string]ddbomb.Attribute, err error) {
+	m.called("Scan", attributeComparisons).into(&items, &err)
+	return
+}
+
+func (m *MockTable) ParallelScan(attributeComparisons []ddbomb.AttributeComparison, segment int, totalSegments int) (items []map[string]ddbomb.Attribute, err error) {
+	m.called("ParallelScan", attributeComparisons, segment, totalSegments).into(&items, &err)
+	return
+}
+
+func (m *MockTable) ScanWithOptions(opts *ddbomb.ScanOptions) (items []map[string]ddbomb.Attribute, err error) {
+	m.called("ScanWithOptions", opts).into(&items, &err)
+	return
+}
+
+func (m *MockTable) ScanPage(opts *ddbomb.ScanOptions) (items []map[string]ddbomb.Attribute, lastEvaluatedKey map[string]interface{}, err error) {
+	m.called("ScanPage", opts).into(&items, &lastEvaluatedKey, &err)
+	return
+}
+
+func (m *MockTable) CountScan(attributeComparisons ...ddbomb.AttributeComparison) (count int64, err error) {
+	m.called("CountScan", attributeComparisons).into(&count, &err)
+	return
+}
+
+func (m *MockTable) CountScanWithOptions(opts *ddbomb.ScanOptions) (count int64, err error) {
+	m.called("CountScanWithOptions", opts).into(&count, &err)
+	return
+}
+
+func (m *MockTable) DescribeTable() (desc *ddbomb.TableDescriptionT, err error) {
+	m.called("DescribeTable").into(&desc, &err)
+	return
+}
+
+func (m *MockTable) Update(update ddbomb.UpdateTableT) (desc *ddbomb.TableDescriptionT, err error) {
+	m.called("Update", update).into(&desc, &err)
+	return
+}
+
+func (m *MockTable) WaitUntilActive(ctx context.Context) (desc *ddbomb.TableDescriptionT, err error) {
+	m.called("WaitUntilActive", ctx).into(&desc, &err)
+	return
+}
+
+func (m *MockTable) WaitUntilDeleted(ctx context.Context) (err error) {
+	m.called("WaitUntilDeleted", ctx).into(&err)
+	return
+}
+
+func (m *MockTable) WaitForIndexActive(ctx context.Context, indexName string) (desc *ddbomb.TableDescriptionT, err error) {
+	m.called("WaitForIndexActive", ctx, indexName).into(&desc, &err)
+	return
+}
+
+func (m *MockTable) EnableTTL(attributeName string) (err error) {
+	m.called("EnableTTL", attributeName).into(&err)
+	return
+}
+
+func (m *MockTable) DisableTTL() (err error) {
+	m.called("DisableTTL").into(&err)
+	return
+}
+
+func (m *MockTable) DescribeTTL() (desc *ddbomb.TimeToLiveDescriptionT, err error) {
+	m.called("DescribeTTL").into(&desc, &err)
+	return
+}
+
+func (m *MockTable) CreateBackup(backupName string) (details *ddbomb.BackupDetailsT, err error) {
+	m.called("CreateBackup", backupName).into(&details, &err)
+	return
+}
+
+func (m *MockTable) ListBackups() (backups []ddbomb.BackupSummaryT, err error) {
+	m.called("ListBackups").into(&backups, &err)
+	return
+}
+
+func (m *MockTable) RestoreToPointInTime(targetTableName string, restoreDateTime time.Time) (desc *ddbomb.TableDescriptionT, err error) {
+	m.called("RestoreToPointInTime", targetTableName, restoreDateTime).into(&desc, &err)
+	return
+}
+
+func (m *MockTable) UpdateContinuousBackups(pointInTimeRecoveryEnabled bool) (desc *ddbomb.ContinuousBackupsDescriptionT, err error) {
+	m.called("UpdateContinuousBackups", pointInTimeRecoveryEnabled).into(&desc, &err)
+	return
+}
+
+func (m *MockTable) DescribeContinuousBackups() (desc *ddbomb.ContinuousBackupsDescriptionT, err error) {
+	m.called("DescribeContinuousBackups").into(&desc, &err)
+	return
+}
@@ -0,0 +1,64 @@
+package ddbombtest_test
+
+import (
+	"errors"
+
+	"github.com/ryansb/dynamodbomb"
+	"github.com/ryansb/dynamodbomb/ddbombtest"
+	"launchpad.net/gocheck"
+)
+
+type MockTableSuite struct{}
+
+var _ = gocheck.Suite(&MockTableSuite{})
+
+// lookup stands for downstream code that only needs to read items.
+func lookup(r ddbomb.ItemReader, id string) (string, error) {
+	item, err := r.GetItem(&ddbomb.Key{HashKey: id})
+	if err != nil {
+		return "", err
+	}
+	return item["Name"].Value, nil
+}
+
+func (s *MockTableSuite) TestRecordsCallsAndReturnsQueuedResults(c *gocheck.C) {
+	m := &ddbombtest.MockTable{}
+	m.On("GetItem", map[string]ddbomb.Attribute{"Name": *ddbomb.NewStringAttribute("Name", "ann")}, nil).
+		On("GetItem", nil, ddbomb.ErrNotFound)
+
+	name, err := lookup(m, "1")
+	c.Check(err, gocheck.IsNil)
+	c.Check(name, gocheck.Equals, "ann")
+
+	for _, id := range []string{"2", "3"} {
+		_, err = lookup(m, id)
+		c.Check(err, gocheck.Equals, ddbomb.ErrNotFound)
+	}
+
+	calls := m.CallsTo("GetItem")
+	c.Assert(calls, gocheck.HasLen, 3)
+	c.Check(calls[2].Args, gocheck.DeepEquals, []interface{}{&ddbomb.Key{HashKey: "3"}})
+}
+
+func (s *MockTableSuite) TestWritesSucceedUnlessAnErrorIsSet(c *gocheck.C) {
+	m := &ddbombtest.MockTable{}
+	ok, err := m.PutItem("1", "", nil)
+	c.Check(ok, gocheck.Equals, true)
+	c.Check(err, gocheck.IsNil)
+
+	failure := errors.New("throttled")
+	m.On("DeleteItem", nil, failure)
+	ok, err = m.DeleteItem(&ddbomb.Key{HashKey: "1"})
+	c.Check(ok, gocheck.Equals, false)
+	c.Check(err, gocheck.Equals, failure)
+
+	c.Check(m.Calls(), gocheck.HasLen, 2)
+}
+
+func (s *MockTableSuite) TestOnRejectsBadResults(c *gocheck.C) {
+	m := &ddbombtest.MockTable{}
+	c.Check(func() { m.On("GetItems") }, gocheck.PanicMatches, ".*GetItems is not a method of ddbomb.TableAPI")
+
+	m.On("CountScan", "3")
+	c.Check(func() { m.CountScan() }, gocheck.PanicMatches, "ddbombtest: result 0 of CountScan must be a int64, not a string")
+}
@@ -1,19 +1,54 @@
 package ddbomb
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
-	simplejson "github.com/bitly/go-simplejson"
-	"github.com/crowdmob/goamz/aws"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"strings"
 	"time"
 )
 
 type Server struct {
-	Auth   aws.Auth
-	Region aws.Region
+	Region Region
+
+	// Credentials provides the credentials of every request;
+	// DefaultCredentials when nil.
+	Credentials CredentialsProvider
+
+	// Transport sends the signed requests; http.DefaultTransport when nil.
+	// See Record and Replay.
+	Transport http.RoundTripper
+
+	// Middleware runs around every operation, see Use.
+	Middleware []Middleware
+
+	// Logger receives diagnostics; nothing is logged when nil.
+	Logger Logger
+
+	// MaxRetries is how many times an operation is resent after a throttle,
+	// a server error or a transport error.
+	MaxRetries int
+
+	// RateLimiter, when set, delays operations to keep the capacity they
+	// consume under per-table limits. It is shared by the copies made by
+	// WithContext.
+	RateLimiter *RateLimiter
+
+	// Throttle, when set, slows down the requests to the tables DynamoDB
+	// throttles.
+	Throttle *AdaptiveThrottle
+
+	// Breaker, when set, fails operations fast after sustained errors.
+	Breaker *CircuitBreaker
+
+	// DisableCRC32Check skips the validation of response bodies against
+	// their X-Amz-Crc32 header.
+	DisableCRC32Check bool
+
+	ctx context.Context // see WithContext
 }
 
 /*
@@ -33,68 +68,107 @@ func NewQuery(queryParts []string) *Query {
 // Specific error constants
 var ErrNotFound = errors.New("Item not found")
 
-// Error represents an error in an operation with Dynamodb (following goamz/s3)
+// Error represents an error in an operation with Dynamodb
 type Error struct {
 	StatusCode int // HTTP status code (200, 403, ...)
 	Status     string
 	Code       string // Dynamodb error code ("MalformedQueryString", ...)
 	Message    string // The human-oriented error message
+	RequestId  string // x-amzn-RequestId, for AWS support
+
+	// CancellationReasons has one entry per item of a transaction canceled
+	// with TransactionCanceledException.
+	CancellationReasons []CancellationReasonT
 }
 
 func (e *Error) Error() string {
 	return e.Code + ": " + e.Message
 }
 
-func buildError(r *http.Response, jsonBody []byte) error {
+func (s *Server) buildError(r *http.Response, jsonBody []byte) error {
 
 	ddbError := Error{
 		StatusCode: r.StatusCode,
 		Status:     r.Status,
+		RequestId:  r.Header.Get("X-Amzn-Requestid"),
 	}
-	// TODO return error if Unmarshal fails?
 
-	json, err := simplejson.NewJson(jsonBody)
-	if err != nil {
-		log.Printf("Failed to parse body as JSON")
-		return err
+	var body struct {
+		Type                string `json:"__type"`
+		LowerMessage        string `json:"message"`
+		Message             string
+		CancellationReasons []CancellationReasonT
+	}
+	if err := json.Unmarshal(jsonBody, &body); err != nil {
+		// Such as an HTML page from a proxy or load balancer.
+		s.log(LOG_WARN, "Failed to parse error response as JSON", "status", r.StatusCode, "error", err)
+		ddbError.Message = r.Status
+		return &ddbError
 	}
-	ddbError.Message = json.Get("message").MustString()
 
 	// Of the form: com.amazon.coral.validate#ValidationException
 	// We only want the last part
-	codeStr := json.Get("__type").MustString()
-	hashIndex := strings.Index(codeStr, "#")
-	if hashIndex > 0 {
-		codeStr = codeStr[hashIndex+1:]
+	ddbError.Code = body.Type[strings.Index(body.Type, "#")+1:]
+	ddbError.Message = body.LowerMessage
+	if ddbError.Message == "" {
+		ddbError.Message = body.Message
 	}
-	ddbError.Code = codeStr
+	ddbError.CancellationReasons = body.CancellationReasons
 
 	return &ddbError
 }
 
 func (s *Server) queryServer(target string, query *Query) ([]byte, error) {
-	data := strings.NewReader(query.String())
-	hreq, err := http.NewRequest("POST", s.Region.DynamoDBEndpoint+"/", data)
+	query = query.WithContext(s.requestContext())
+	// Middleware may change the parameters, which mustn't leak into the
+	// query of the caller: it is sent again for the next page.
+	buffer := make(msi, len(query.buffer))
+	for k, v := range query.buffer {
+		buffer[k] = v
+	}
+	query.buffer = buffer
+	if query.state == nil {
+		query.state = &queryState{}
+	}
+
+	prefix := target[:strings.LastIndex(target, ".")+1]
+	var handler Handler = func(operation string, query *Query) ([]byte, error) {
+		return s.sendWithRetries(prefix+operation, query)
+	}
+	for i := len(s.Middleware) - 1; i >= 0; i-- {
+		handler = s.Middleware[i](handler)
+	}
+	return s.breakCircuit(strings.TrimPrefix(target, prefix), query, func(operation string, query *Query) ([]byte, error) {
+		return s.limitRate(operation, query, handler)
+	})
+}
+
+func (s *Server) send(target string, query *Query) ([]byte, error) {
+	data := []byte(query.String())
+	hreq, err := http.NewRequestWithContext(query.Context(), "POST", s.Region.DynamoDBEndpoint+"/", bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
 
+	creds, err := s.credentials(query.Context())
+	if err != nil {
+		s.log(LOG_ERROR, "Could not get credentials", "target", target, "error", err)
+		return nil, err
+	}
+
 	hreq.Header.Set("Content-Type", "application/x-amz-json-1.0")
-	hreq.Header.Set("X-Amz-Date", time.Now().UTC().Format(aws.ISO8601BasicFormat))
 	hreq.Header.Set("X-Amz-Target", target)
+	signV4(hreq, data, creds, s.Region.signingRegion(), "dynamodb", time.Now())
 
-	token := s.Auth.Token()
-	if token != "" {
-		hreq.Header.Set("X-Amz-Security-Token", token)
+	client := http.DefaultClient
+	if s.Transport != nil {
+		client = &http.Client{Transport: s.Transport}
 	}
-
-	signer := aws.NewV4Signer(s.Auth, "dynamodb", s.Region)
-	signer.Sign(hreq)
-
-	resp, err := http.DefaultClient.Do(hreq)
+	start := time.Now()
+	resp, err := client.Do(hreq)
 
 	if err != nil {
-		log.Printf("Error calling Amazon")
+		s.log(LOG_ERROR, "Error calling DynamoDB", "target", target, "error", err)
 		return nil, err
 	}
 
@@ -102,20 +176,43 @@ func (s *Server) queryServer(target string, query *Query) ([]byte, error) {
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("Could not read response body")
+		s.log(LOG_ERROR, "Could not read response body", "target", target, "status", resp.StatusCode, "error", err)
+		return nil, err
+	}
+
+	if err := s.checkCRC32(resp, body); err != nil {
+		s.log(LOG_WARN, "Corrupted response", "target", target, "status", resp.StatusCode, "error", err)
 		return nil, err
 	}
 
+	if s.logEnabled(LOG_DEBUG) {
+		s.log(LOG_DEBUG, "DynamoDB request",
+			"target", target,
+			"status", resp.StatusCode,
+			"latency", time.Since(start),
+			"request", redactBody(data),
+			"response", redactBody(body))
+	}
+
 	// http://docs.aws.amazon.com/amazondynamodb/latest/developerguide/ErrorHandling.html
 	// "A response code of 200 indicates the operation was successful."
 	if resp.StatusCode != 200 {
-		ddbErr := buildError(resp, body)
+		ddbErr := s.buildError(resp, body)
 		return nil, ddbErr
 	}
 
 	return body, nil
 }
 
+// queryServerInto runs the query and decodes the JSON response into v.
+func (s *Server) queryServerInto(target string, query *Query, v interface{}) error {
+	jsonResponse, err := s.queryServer(target, query)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonResponse, v)
+}
+
 func target(name string) string {
 	return "DynamoDB_20120810." + name
 }
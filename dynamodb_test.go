@@ -1,25 +1,29 @@
 package ddbomb_test
 
 import (
+	"context"
 	"flag"
-	"github.com/crowdmob/goamz/aws"
+	"fmt"
 	"github.com/ryansb/dynamodbomb"
+	"github.com/ryansb/dynamodbomb/ddbombtest"
+	"io/ioutil"
 	"launchpad.net/gocheck"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
 
 const TIMEOUT = 3 * time.Minute
 
-var amazon = flag.Bool("amazon", false, "Enable tests against dynamodb")
+var amazon = flag.Bool("amazon", false, "Enable tests against dynamodb instead of the in-memory fake")
 var local = flag.Bool("local", true, "Use DynamoDB local on 8080 instead of real server on us-east.")
 
-var dynamodb_region aws.Region
-var dynamodb_auth aws.Auth
+var fakeServer *httptest.Server
 
 type DynamoDBTest struct {
 	server            *ddbomb.Server
-	aws.Region        // Exports Region
+	ddbomb.Region     // Exports Region
 	TableDescriptionT ddbomb.TableDescriptionT
 	table             *ddbomb.Table
 }
@@ -31,7 +35,7 @@ func (s *DynamoDBTest) TearDownTest(c *gocheck.C) {
 		c.Fatal(err)
 	}
 
-	attrs, err := s.table.Scan(nil)
+	attrs, err := s.table.Scan()
 	if err != nil {
 		c.Fatal(err)
 	}
@@ -68,84 +72,85 @@ func (s *DynamoDBTest) TearDownSuite(c *gocheck.C) {
 		c.Fatal(err)
 	}
 
-	done := make(chan bool)
-	timeout := time.After(TIMEOUT)
-	go func() {
-		for {
-			select {
-			case <-done:
-				return
-			default:
-				tables, err := s.server.ListTables()
-				if err != nil {
-					c.Fatal(err)
-				}
-				if findTableByName(tables, s.TableDescriptionT.TableName) {
-					time.Sleep(5 * time.Second)
-				} else {
-					done <- true
-					return
-				}
-			}
-		}
-	}()
-	select {
-	case <-done:
-		break
-	case <-timeout:
-		c.Error("Expect the table to be deleted but timed out")
-		close(done)
+	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	defer cancel()
+	if err := s.server.WaitUntilTableDeleted(ctx, s.TableDescriptionT.TableName); err != nil {
+		c.Errorf("Expect the table to be deleted but got %s", err)
 	}
 }
 
-func (s *DynamoDBTest) WaitUntilStatus(c *gocheck.C, status string) {
-	// We should wait until the table is in specified status because a real DynamoDB has some delay for ready
-	done := make(chan bool)
-	timeout := time.After(TIMEOUT)
-	go func() {
-		for {
-			select {
-			case <-done:
-				return
-			default:
-				desc, err := s.table.DescribeTable()
-				if err != nil {
-					c.Fatal(err)
-				}
-				if desc.TableStatus == status {
-					done <- true
-					return
-				}
-				time.Sleep(5 * time.Second)
-			}
-		}
-	}()
-	select {
-	case <-done:
-		break
-	case <-timeout:
-		c.Errorf("Expect a status to be %s, but timed out", status)
-		close(done)
+func (s *DynamoDBTest) WaitUntilActive(c *gocheck.C) {
+	// We should wait until the table is active because a real DynamoDB has some delay for ready
+	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	defer cancel()
+	if _, err := s.table.WaitUntilActive(ctx); err != nil {
+		c.Errorf("Expect the table to be ACTIVE, but got %s", err)
 	}
 }
 
-func setUpAuth(c *gocheck.C) {
+// setUpServer returns a Server for the fake, DynamoDB Local or AWS,
+// depending on the flags.
+func setUpServer(c *gocheck.C) *ddbomb.Server {
 	if !*amazon {
-		c.Skip("Test against amazon not enabled.")
-	}
-	if *local {
-		c.Log("Using local server")
-		dynamodb_region = aws.Region{DynamoDBEndpoint: "http://127.0.0.1:8000"}
-		dynamodb_auth = aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}
-	} else {
-		c.Log("Using REAL AMAZON SERVER")
-		dynamodb_region = aws.USEast
-		auth, err := aws.EnvAuth()
-		if err != nil {
-			c.Fatal(err)
+		if fakeServer == nil {
+			fakeServer = httptest.NewServer(ddbombtest.NewFake())
 		}
-		dynamodb_auth = auth
+		c.Log("Using in-memory fake")
+		return ddbomb.NewLocalServer(fakeServer.URL)
+	} else if *local {
+		c.Log("Using local server")
+		return ddbomb.NewLocalServer("http://127.0.0.1:8000")
+	}
+
+	c.Log("Using REAL AMAZON SERVER")
+	server, err := ddbomb.NewServer("us-east-1", ddbomb.WithCredentials(ddbomb.EnvCredentials{}))
+	if err != nil {
+		c.Fatal(err)
+	}
+	if _, err := server.Credentials.Retrieve(context.Background()); err != nil {
+		c.Fatal(err)
 	}
+	return server
+}
+
+// newTestServer starts h on a local listener and returns a Server pointed
+// at it, for tests that need neither DynamoDB Local nor AWS.
+func newTestServer(h http.Handler) (*httptest.Server, *ddbomb.Server) {
+	ts := httptest.NewServer(h)
+	return ts, ddbomb.NewLocalServer(ts.URL)
+}
+
+// cannedHandler answers every request with the next canned response,
+// repeating the last one once they run out. An empty response is answered
+// with a ResourceNotFoundException.
+func cannedHandler(responses ...string) http.Handler {
+	calls := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := responses[calls]
+		if calls < len(responses)-1 {
+			calls++
+		}
+		if body == "" {
+			w.WriteHeader(400)
+			fmt.Fprint(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`)
+			return
+		}
+		fmt.Fprint(w, body)
+	})
+}
+
+func cannedServer(responses ...string) (*httptest.Server, *ddbomb.Server) {
+	return newTestServer(cannedHandler(responses...))
+}
+
+// recordTargets wraps h, appending the target and body of every request
+// it sees to requests.
+func recordTargets(h http.Handler, requests *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, r.Header.Get("X-Amz-Target")+" "+string(body))
+		h.ServeHTTP(w, r)
+	})
 }
 
 func findTableByName(tables []string, name string) bool {
@@ -0,0 +1,115 @@
+package ddbomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors matched by errors.Is against the *Error returned for the
+// corresponding DynamoDB error code:
+//
+//	if errors.Is(err, ddbomb.ErrConditionalCheckFailed) { ... }
+var (
+	ErrConditionalCheckFailed        = errors.New("ConditionalCheckFailedException")
+	ErrProvisionedThroughputExceeded = errors.New("ProvisionedThroughputExceededException")
+	ErrRequestLimitExceeded          = errors.New("RequestLimitExceeded")
+	ErrThrottling                    = errors.New("ThrottlingException")
+	ErrResourceNotFound              = errors.New("ResourceNotFoundException")
+	ErrResourceInUse                 = errors.New("ResourceInUseException")
+	ErrValidation                    = errors.New("ValidationException")
+	ErrTransactionCanceled           = errors.New("TransactionCanceledException")
+	ErrTransactionConflict           = errors.New("TransactionConflictException")
+	ErrItemCollectionSizeLimit       = errors.New("ItemCollectionSizeLimitExceededException")
+	ErrLimitExceeded                 = errors.New("LimitExceededException")
+	ErrBackupNotFound                = errors.New("BackupNotFoundException")
+	ErrInternalServer                = errors.New("InternalServerError")
+)
+
+var codeErrors = map[string]error{}
+
+func init() {
+	for _, err := range []error{
+		ErrConditionalCheckFailed, ErrProvisionedThroughputExceeded, ErrRequestLimitExceeded,
+		ErrThrottling, ErrResourceNotFound, ErrResourceInUse, ErrValidation,
+		ErrTransactionCanceled, ErrTransactionConflict, ErrItemCollectionSizeLimit,
+		ErrLimitExceeded, ErrBackupNotFound, ErrInternalServer,
+	} {
+		codeErrors[err.Error()] = err
+	}
+}
+
+// ErrUnexpectedResponse is matched by errors.Is for responses that don't
+// have the shape the operation expects.
+var ErrUnexpectedResponse = errors.New("Unexpected response")
+
+// ErrUnprocessedItems is returned by BatchWriteItem.Execute along with the
+// items DynamoDB left unprocessed.
+var ErrUnprocessedItems = errors.New("One or more unprocessed items.")
+
+// maxUnexpectedResponse is how much of a response unexpectedResponse keeps.
+const maxUnexpectedResponse = 256
+
+// unexpectedResponse keeps item data out of the error, which tends to end
+// up in logs, by redacting the response and cutting it short.
+func unexpectedResponse(response []byte) error {
+	body := redactBody(response)
+	if len(body) > maxUnexpectedResponse {
+		body = body[:maxUnexpectedResponse] + "..."
+	}
+	return fmt.Errorf("%w %s", ErrUnexpectedResponse, body)
+}
+
+// Error codes that mean the request was throttled.
+var throttleCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"ThrottlingException":                    true,
+	"RequestLimitExceeded":                   true,
+}
+
+// CancellationReasonT explains, for one item of a canceled transaction, why
+// it was canceled. Code is "None" for the items that weren't the cause.
+type CancellationReasonT struct {
+	Code    string
+	Message string `json:",omitempty"`
+}
+
+// Is matches the sentinel error for the code of e, such as
+// ErrConditionalCheckFailed.
+func (e *Error) Is(target error) bool {
+	sentinel, ok := codeErrors[e.Code]
+	return ok && sentinel == target
+}
+
+// IsThrottle reports whether the request was rejected because of
+// throttling, either of the table or of the account.
+func (e *Error) IsThrottle() bool {
+	return throttleCodes[e.Code]
+}
+
+// IsRetryable reports whether sending the same request again may succeed.
+func (e *Error) IsRetryable() bool {
+	return e.StatusCode >= 500 || e.IsThrottle() || e.Code == "TransactionConflictException"
+}
+
+// IsThrottle reports whether err is a throttling *Error.
+func IsThrottle(err error) bool {
+	var ddbErr *Error
+	return errors.As(err, &ddbErr) && ddbErr.IsThrottle()
+}
+
+// IsRetryable reports whether sending the request that failed with err again
+// may succeed. Besides retryable *Errors, that's the case of transport
+// errors and unreadable responses, but not of a done context, of missing
+// credentials or of an open circuit breaker.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrNoCredentials) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var ddbErr *Error
+	if errors.As(err, &ddbErr) {
+		return ddbErr.IsRetryable()
+	}
+	return !errors.Is(err, ErrUnexpectedResponse)
+}
@@ -0,0 +1,118 @@
+package ddbomb_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type ErrorSuite struct{}
+
+var _ = gocheck.Suite(&ErrorSuite{})
+
+func errorHandler(status int, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amzn-RequestId", "REQ123")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+}
+
+func (s *ErrorSuite) TestSentinelsAndRequestId(c *gocheck.C) {
+	ts, server := newTestServer(errorHandler(400,
+		`{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`))
+	defer ts.Close()
+
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	_, err := table.PutItem("example.com", "", []ddbomb.Attribute{*ddbomb.NewStringAttribute("Owner", "ann")})
+
+	c.Check(errors.Is(err, ddbomb.ErrConditionalCheckFailed), gocheck.Equals, true)
+	c.Check(errors.Is(err, ddbomb.ErrValidation), gocheck.Equals, false)
+	c.Check(ddbomb.IsRetryable(err), gocheck.Equals, false)
+
+	var ddbErr *ddbomb.Error
+	c.Assert(errors.As(err, &ddbErr), gocheck.Equals, true)
+	c.Check(ddbErr.RequestId, gocheck.Equals, "REQ123")
+	c.Check(ddbErr.Error(), gocheck.Equals, "ConditionalCheckFailedException: The conditional request failed")
+}
+
+func (s *ErrorSuite) TestTransactionCanceled(c *gocheck.C) {
+	ts, server := newTestServer(errorHandler(400,
+		`{"__type":"com.amazonaws.dynamodb.v20120810#TransactionCanceledException","Message":"Transaction cancelled",
+		  "CancellationReasons":[{"Code":"None"},{"Code":"ConditionalCheckFailed","Message":"The conditional request failed"}]}`))
+	defer ts.Close()
+
+	_, err := server.DescribeTable("sites")
+	c.Check(errors.Is(err, ddbomb.ErrTransactionCanceled), gocheck.Equals, true)
+
+	var ddbErr *ddbomb.Error
+	c.Assert(errors.As(err, &ddbErr), gocheck.Equals, true)
+	c.Check(ddbErr.Message, gocheck.Equals, "Transaction cancelled")
+	c.Check(ddbErr.CancellationReasons, gocheck.DeepEquals, []ddbomb.CancellationReasonT{
+		{Code: "None"},
+		{Code: "ConditionalCheckFailed", Message: "The conditional request failed"},
+	})
+}
+
+func (s *ErrorSuite) TestThrottleAndServerErrors(c *gocheck.C) {
+	throttle := &ddbomb.Error{StatusCode: 400, Code: "ProvisionedThroughputExceededException"}
+	c.Check(throttle.IsThrottle(), gocheck.Equals, true)
+	c.Check(throttle.IsRetryable(), gocheck.Equals, true)
+	c.Check(errors.Is(fmt.Errorf("saving: %w", throttle), ddbomb.ErrProvisionedThroughputExceeded), gocheck.Equals, true)
+	c.Check(ddbomb.IsThrottle(fmt.Errorf("saving: %w", throttle)), gocheck.Equals, true)
+
+	internal := &ddbomb.Error{StatusCode: 500, Code: "InternalServerError"}
+	c.Check(internal.IsThrottle(), gocheck.Equals, false)
+	c.Check(internal.IsRetryable(), gocheck.Equals, true)
+
+	c.Check(ddbomb.IsRetryable(errors.New("connection reset by peer")), gocheck.Equals, true)
+	c.Check(ddbomb.IsRetryable(nil), gocheck.Equals, false)
+}
+
+func (s *ErrorSuite) TestUnparseableErrorBody(c *gocheck.C) {
+	ts, server := newTestServer(errorHandler(502, "<html>Bad Gateway</html>"))
+	defer ts.Close()
+
+	_, err := server.DescribeTable("sites")
+	var ddbErr *ddbomb.Error
+	c.Assert(errors.As(err, &ddbErr), gocheck.Equals, true)
+	c.Check(ddbErr.StatusCode, gocheck.Equals, 502)
+	c.Check(ddbErr.RequestId, gocheck.Equals, "REQ123")
+	c.Check(ddbomb.IsRetryable(err), gocheck.Equals, true)
+}
+
+func (s *ErrorSuite) TestUnexpectedResponse(c *gocheck.C) {
+	ts, server := cannedServer(`{"Item": "not a map"}`)
+	defer ts.Close()
+
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	_, err := table.GetItem(&ddbomb.Key{HashKey: "example.com"})
+	c.Check(errors.Is(err, ddbomb.ErrUnexpectedResponse), gocheck.Equals, true)
+	c.Check(err, gocheck.ErrorMatches, `Unexpected response {"Item":"not a map"}`)
+	c.Check(ddbomb.IsRetryable(err), gocheck.Equals, false)
+}
+
+func (s *ErrorSuite) TestUnexpectedResponseIsRedacted(c *gocheck.C) {
+	ts, server := cannedServer(`{"Items": [{"Domain": {"S": "example.com"}, "Owner": {"S": "ann"}}]}`)
+	defer ts.Close()
+
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	_, err := table.Scan()
+	c.Check(errors.Is(err, ddbomb.ErrUnexpectedResponse), gocheck.Equals, true)
+	c.Check(err, gocheck.ErrorMatches, `Unexpected response {"Items":\[{"Domain":{"S":"REDACTED"},"Owner":{"S":"REDACTED"}}\]}`)
+}
+
+func (s *ErrorSuite) TestUnprocessedItems(c *gocheck.C) {
+	ts, server := cannedServer(`{"UnprocessedItems": {"sites": [{"DeleteRequest": {"Key": {"Domain": {"S": "a.com"}}}}]}}`)
+	defer ts.Close()
+
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	unprocessed, err := table.BatchWriteItems(map[string][][]ddbomb.Attribute{
+		"Delete": {{*ddbomb.NewStringAttribute("Domain", "a.com")}},
+	}).Execute()
+	c.Check(err, gocheck.Equals, ddbomb.ErrUnprocessedItems)
+	c.Check(unprocessed, gocheck.HasLen, 1)
+}
@@ -0,0 +1,4 @@
+package ddbomb
+
+// Exported for the tests of the ddbomb_test package.
+var SignV4 = signV4
@@ -2,9 +2,7 @@ package ddbomb
 
 import (
 	"errors"
-	"fmt"
 	simplejson "github.com/bitly/go-simplejson"
-	"log"
 )
 
 type BatchGetItem struct {
@@ -60,8 +58,7 @@ func (batchGetItem *BatchGetItem) Execute() (map[string][]map[string]Attribute,
 
 	tables, err := json.Get("Responses").Map()
 	if err != nil {
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
+		return nil, unexpectedResponse(jsonResponse)
 	}
 
 	for table, entries := range tables {
@@ -69,18 +66,16 @@ func (batchGetItem *BatchGetItem) Execute() (map[string][]map[string]Attribute,
 
 		jsonEntriesArray, ok := entries.([]interface{})
 		if !ok {
-			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-			return nil, errors.New(message)
+			return nil, unexpectedResponse(jsonResponse)
 		}
 
 		for _, entry := range jsonEntriesArray {
 			item, ok := entry.(map[string]interface{})
 			if !ok {
-				message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-				return nil, errors.New(message)
+				return nil, unexpectedResponse(jsonResponse)
 			}
 
-			unmarshalledItem := parseAttributes(item)
+			unmarshalledItem := batchGetItem.Server.parseAttributes(item)
 			tableResult = append(tableResult, unmarshalledItem)
 		}
 
@@ -108,14 +103,13 @@ func (batchWriteItem *BatchWriteItem) Execute() (map[string]interface{}, error)
 
 	unprocessed, err := json.Get("UnprocessedItems").Map()
 	if err != nil {
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
+		return nil, unexpectedResponse(jsonResponse)
 	}
 
 	if len(unprocessed) == 0 {
 		return nil, nil
 	} else {
-		return unprocessed, errors.New("One or more unprocessed items.")
+		return unprocessed, ErrUnprocessedItems
 	}
 
 }
@@ -154,11 +148,10 @@ func (t *Table) getItem(key *Key, consistentRead bool) (map[string]Attribute, er
 
 	item, err := itemJson.Map()
 	if err != nil {
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
+		return nil, unexpectedResponse(jsonResponse)
 	}
 
-	return parseAttributes(item), nil
+	return t.Server.parseAttributes(item), nil
 }
 
 func (t *Table) PutItem(hashKey string, rangeKey string, attributes []Attribute) (bool, error) {
@@ -280,9 +273,9 @@ func (t *Table) modifyAttributes(key *Key, attributes, expected []Attribute, act
 	return true, nil
 }
 
-func parseAttributes(s map[string]interface{}) map[string]Attribute {
+func (s *Server) parseAttributes(item map[string]interface{}) map[string]Attribute {
 	results := make(map[string]Attribute)
-	for key, value := range s {
+	for key, value := range item {
 		if v, ok := value.(map[string]interface{}); ok {
 			if val, ok := v[string(STRING)].(string); ok {
 				results[key] = Attribute{
@@ -340,7 +333,7 @@ func parseAttributes(s map[string]interface{}) map[string]Attribute {
 				}
 			}
 		} else {
-			log.Printf("type assertion to map[string] interface{} failed for : %s\n ", value)
+			s.log(LOG_WARN, "Skipping attribute that isn't an attribute value", "attribute", key)
 		}
 
 	}
@@ -12,9 +12,8 @@ type ItemSuite struct {
 }
 
 func (s *ItemSuite) SetUpSuite(c *gocheck.C) {
-	setUpAuth(c)
 	s.DynamoDBTest.TableDescriptionT = s.TableDescriptionT
-	s.server = &ddbomb.Server{dynamodb_auth, dynamodb_region}
+	s.server = setUpServer(c)
 	pk, err := s.TableDescriptionT.BuildPrimaryKey()
 	if err != nil {
 		c.Skip(err.Error())
@@ -27,7 +26,7 @@ func (s *ItemSuite) SetUpSuite(c *gocheck.C) {
 	if err != nil {
 		c.Fatal(err)
 	}
-	s.WaitUntilStatus(c, "ACTIVE")
+	s.WaitUntilActive(c)
 }
 
 var item_suite = &ItemSuite{
@@ -156,7 +155,7 @@ func (s *ItemSuite) TestConditionalPutUpdateDeleteItem(c *gocheck.C) {
 		}
 
 		if val, ok := item["AddNewAttr1"]; ok {
-			c.Check(val, gocheck.DeepEquals, ddbomb.NewNumericAttribute("AddNewAttr1", "100"))
+			c.Check(val, gocheck.DeepEquals, *ddbomb.NewNumericAttribute("AddNewAttr1", "100"))
 		} else {
 			c.Error("Expect AddNewAttr1 attribute to be added and updated")
 		}
@@ -185,7 +184,7 @@ func (s *ItemSuite) TestConditionalPutUpdateDeleteItem(c *gocheck.C) {
 		}
 
 		if val, ok := item["Attr1"]; ok {
-			c.Check(val, gocheck.DeepEquals, ddbomb.NewStringAttribute("Attr1", "Attr2Val"))
+			c.Check(val, gocheck.DeepEquals, *ddbomb.NewStringAttribute("Attr1", "Attr2Val"))
 		} else {
 			c.Error("Expect Attr1 attribute to be updated")
 		}
@@ -241,14 +240,14 @@ func (s *ItemSuite) TestPutGetDeleteItem(c *gocheck.C) {
 	}
 
 	if val, ok := item["TestHashKey"]; ok {
-		c.Check(val, gocheck.DeepEquals, ddbomb.NewStringAttribute("TestHashKey", "NewHashKeyVal"))
+		c.Check(val, gocheck.DeepEquals, *ddbomb.NewStringAttribute("TestHashKey", "NewHashKeyVal"))
 	} else {
 		c.Error("Expect TestHashKey to be found")
 	}
 
 	if s.WithRange {
 		if val, ok := item["TestRangeKey"]; ok {
-			c.Check(val, gocheck.DeepEquals, ddbomb.NewNumericAttribute("TestRangeKey", "1"))
+			c.Check(val, gocheck.DeepEquals, *ddbomb.NewNumericAttribute("TestRangeKey", "1"))
 		} else {
 			c.Error("Expect TestRangeKey to be found")
 		}
@@ -292,7 +291,7 @@ func (s *ItemSuite) TestUpdateItem(c *gocheck.C) {
 		c.Error(err)
 	} else {
 		if val, ok := item["count"]; ok {
-			c.Check(val, gocheck.DeepEquals, ddbomb.NewNumericAttribute("count", "10"))
+			c.Check(val, gocheck.DeepEquals, *ddbomb.NewNumericAttribute("count", "10"))
 		} else {
 			c.Error("Expect count to be found")
 		}
@@ -311,7 +310,7 @@ func (s *ItemSuite) TestUpdateItem(c *gocheck.C) {
 		c.Fatal(err)
 	} else {
 		if val, ok := item["count"]; ok {
-			c.Check(val, gocheck.DeepEquals, ddbomb.NewNumericAttribute("count", "100"))
+			c.Check(val, gocheck.DeepEquals, *ddbomb.NewNumericAttribute("count", "100"))
 		} else {
 			c.Error("Expect count to be found")
 		}
@@ -363,7 +362,7 @@ func (s *ItemSuite) TestUpdateItemWithSet(c *gocheck.C) {
 		c.Error(err)
 	} else {
 		if val, ok := item["list"]; ok {
-			c.Check(val, gocheck.DeepEquals, ddbomb.NewStringSetAttribute("list", []string{"A", "B", "C"}))
+			c.Check(val, gocheck.DeepEquals, *ddbomb.NewStringSetAttribute("list", []string{"A", "B", "C"}))
 		} else {
 			c.Error("Expect count to be found")
 		}
@@ -382,9 +381,95 @@ func (s *ItemSuite) TestUpdateItemWithSet(c *gocheck.C) {
 		c.Error(err)
 	} else {
 		if val, ok := item["list"]; ok {
-			c.Check(val, gocheck.DeepEquals, ddbomb.NewStringSetAttribute("list", []string{"B", "C"}))
+			c.Check(val, gocheck.DeepEquals, *ddbomb.NewStringSetAttribute("list", []string{"B", "C"}))
 		} else {
 			c.Error("Expect list to be remained")
 		}
 	}
 }
+
+func (s *ItemSuite) TestScanWithOptionsAndCountScan(c *gocheck.C) {
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+
+	for _, hk := range []string{"ScanHashKeyVal1", "ScanHashKeyVal2", "ScanHashKeyVal3"} {
+		attrs := []ddbomb.Attribute{
+			*ddbomb.NewStringAttribute("Attr1", hk),
+		}
+		if ok, err := s.table.PutItem(hk, rk, attrs); !ok {
+			c.Fatal(err)
+		}
+	}
+
+	count, err := s.table.CountScanWithOptions(&ddbomb.ScanOptions{Limit: 1})
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(count, gocheck.Equals, int64(3))
+
+	count, err = s.table.CountScan(*ddbomb.NewStringAttributeComparison("Attr1", ddbomb.CMP_EQUAL, "ScanHashKeyVal2"))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(count, gocheck.Equals, int64(1))
+
+	items, err := s.table.ScanWithOptions(&ddbomb.ScanOptions{
+		AttributesToGet: []string{"TestHashKey"},
+		ConsistentRead:  true,
+	})
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(len(items), gocheck.Equals, 3)
+	for _, item := range items {
+		if _, ok := item["Attr1"]; ok {
+			c.Error("Expect Attr1 to be projected out")
+		}
+	}
+}
+
+func (s *ItemSuite) TestScanPage(c *gocheck.C) {
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+
+	for _, hk := range []string{"PageHashKeyVal1", "PageHashKeyVal2", "PageHashKeyVal3"} {
+		attrs := []ddbomb.Attribute{
+			*ddbomb.NewStringAttribute("Attr1", hk),
+		}
+		if ok, err := s.table.PutItem(hk, rk, attrs); !ok {
+			c.Fatal(err)
+		}
+	}
+
+	items, err := s.table.ScanWithOptions(nil)
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(len(items), gocheck.Equals, 3)
+
+	seen := map[string]bool{}
+	opts := &ddbomb.ScanOptions{Limit: 2}
+	for pages := 1; ; pages++ {
+		items, lastKey, err := s.table.ScanPage(opts)
+		if err != nil {
+			c.Fatal(err)
+		}
+		for _, item := range items {
+			seen[item["TestHashKey"].Value] = true
+		}
+		if lastKey == nil {
+			break
+		}
+		if pages > 3 {
+			c.Fatal("Expect the scan to end after 2 or 3 pages")
+		}
+		opts.ExclusiveStartKey = lastKey
+	}
+	c.Check(seen, gocheck.DeepEquals, map[string]bool{
+		"PageHashKeyVal1": true, "PageHashKeyVal2": true, "PageHashKeyVal3": true,
+	})
+}
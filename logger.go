@@ -0,0 +1,104 @@
+package ddbomb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+// LogLevel has the same values as slog.Level.
+type LogLevel int
+
+const (
+	LOG_DEBUG LogLevel = -4
+	LOG_INFO           = 0
+	LOG_WARN           = 4
+	LOG_ERROR          = 8
+)
+
+// Logger receives the diagnostics of a Server. keyvals alternate keys and
+// values, as with log/slog.
+//
+// At LOG_DEBUG every request is logged with its target, status and latency,
+// and with request and response bodies whose attribute values are redacted.
+type Logger interface {
+	Enabled(level LogLevel) bool
+	Log(level LogLevel, msg string, keyvals ...interface{})
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger writing to logger.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger}
+}
+
+func (l slogLogger) Enabled(level LogLevel) bool {
+	return l.logger.Enabled(context.Background(), slog.Level(level))
+}
+
+func (l slogLogger) Log(level LogLevel, msg string, keyvals ...interface{}) {
+	l.logger.Log(context.Background(), slog.Level(level), msg, keyvals...)
+}
+
+// logEnabled reports whether s has a Logger that wants level.
+func (s *Server) logEnabled(level LogLevel) bool {
+	return s != nil && s.Logger != nil && s.Logger.Enabled(level)
+}
+
+// log sends a diagnostic to the Logger of s. Nothing is logged without one.
+func (s *Server) log(level LogLevel, msg string, keyvals ...interface{}) {
+	if s.logEnabled(level) {
+		s.Logger.Log(level, msg, keyvals...)
+	}
+}
+
+const redacted = "REDACTED"
+
+// attributeValueTypes are the keys of a DynamoDB attribute value.
+var attributeValueTypes = map[string]bool{
+	"S": true, "N": true, "B": true, "SS": true, "NS": true, "BS": true,
+	"BOOL": true, "NULL": true, "M": true, "L": true,
+}
+
+// redactBody returns a JSON body with every attribute value replaced, so
+// that item data stays out of the logs while attribute names and the rest
+// of the request remain.
+func redactBody(body []byte) string {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return redacted
+	}
+	out, _ := json.Marshal(redactValue(v))
+	return string(out)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 1 {
+			for typ := range v {
+				if attributeValueTypes[typ] {
+					return map[string]interface{}{typ: redacted}
+				}
+			}
+		}
+		out := make(map[string]interface{}, len(v))
+		for name, value := range v {
+			out[name] = redactValue(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = redactValue(value)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,96 @@
+package ddbomb_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type LoggerSuite struct{}
+
+var _ = gocheck.Suite(&LoggerSuite{})
+
+// logLines decodes the records written by a slog.JSONHandler.
+func logLines(c *gocheck.C, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		c.Assert(json.Unmarshal([]byte(line), &record), gocheck.IsNil)
+		lines = append(lines, record)
+	}
+	return lines
+}
+
+func (s *LoggerSuite) TestDebugWireLoggingRedactsItems(c *gocheck.C) {
+	ts, server := cannedServer(`{"Item": {"Domain": {"S": "example.com"}, "Owner": {"S": "secret owner"}}}`)
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	server.Logger = ddbomb.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	item, err := table.GetItem(&ddbomb.Key{HashKey: "example.com"})
+	c.Assert(err, gocheck.IsNil)
+	c.Check(item["Owner"].Value, gocheck.Equals, "secret owner")
+
+	c.Check(strings.Contains(buf.String(), "example.com"), gocheck.Equals, false)
+	c.Check(strings.Contains(buf.String(), "secret owner"), gocheck.Equals, false)
+
+	lines := logLines(c, &buf)
+	c.Assert(lines, gocheck.HasLen, 1)
+	c.Check(lines[0]["level"], gocheck.Equals, "DEBUG")
+	c.Check(lines[0]["target"], gocheck.Equals, "DynamoDB_20120810.GetItem")
+	c.Check(lines[0]["status"], gocheck.Equals, float64(200))
+	c.Check(lines[0]["request"], gocheck.Equals, `{"Key":{"Domain":{"S":"REDACTED"}},"TableName":"sites"}`)
+	c.Check(lines[0]["response"], gocheck.Equals, `{"Item":{"Domain":{"S":"REDACTED"},"Owner":{"S":"REDACTED"}}}`)
+	_, ok := lines[0]["latency"]
+	c.Check(ok, gocheck.Equals, true)
+}
+
+func (s *LoggerSuite) TestWarningsWithoutValues(c *gocheck.C) {
+	ts, server := newTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(502)
+		w.Write([]byte("<html>Bad Gateway</html>"))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	server.Logger = ddbomb.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	_, err := server.DescribeTable("sites")
+	c.Check(err, gocheck.NotNil)
+
+	lines := logLines(c, &buf)
+	c.Assert(lines, gocheck.HasLen, 1)
+	c.Check(lines[0]["level"], gocheck.Equals, "WARN")
+	c.Check(lines[0]["msg"], gocheck.Equals, "Failed to parse error response as JSON")
+	c.Check(lines[0]["status"], gocheck.Equals, float64(502))
+}
+
+func (s *LoggerSuite) TestNoLoggerIsSilent(c *gocheck.C) {
+	ts, server := cannedServer(`{"Item": {"Domain": "not an attribute value"}}`)
+	defer ts.Close()
+
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	item, err := table.GetItem(&ddbomb.Key{HashKey: "example.com"})
+	c.Assert(err, gocheck.IsNil)
+	c.Check(item, gocheck.HasLen, 0)
+
+	var buf bytes.Buffer
+	server.Logger = ddbomb.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	_, err = table.GetItem(&ddbomb.Key{HashKey: "example.com"})
+	c.Assert(err, gocheck.IsNil)
+
+	lines := logLines(c, &buf)
+	c.Assert(lines, gocheck.HasLen, 1)
+	c.Check(lines[0]["attribute"], gocheck.Equals, "Domain")
+	c.Check(strings.Contains(buf.String(), "not an attribute value"), gocheck.Equals, false)
+}
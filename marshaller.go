@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 )
 
@@ -32,7 +33,12 @@ func MarshalAttributes(m interface{}) ([]Attribute, error) {
 			continue
 		}
 
-		err := builder.reflectToDynamoDBAttribute(f.name, fv)
+		var err error
+		if f.ttl {
+			err = builder.reflectToTTLAttribute(f.name, fv)
+		} else {
+			err = builder.reflectToDynamoDBAttribute(f.name, fv)
+		}
 		if err != nil {
 			return builder.buffer, err
 		}
@@ -55,7 +61,12 @@ func UnmarshalAttributes(attributes map[string]Attribute, m interface{}) error {
 		if !exists {
 			continue
 		}
-		err := unmarshallAttribute(&correlatedAttribute, fv)
+		var err error
+		if f.ttl && f.typ == timeType {
+			err = unmarshallTTLAttribute(&correlatedAttribute, fv)
+		} else {
+			err = unmarshallAttribute(&correlatedAttribute, fv)
+		}
 		if err != nil {
 			return err
 		}
@@ -64,6 +75,8 @@ func UnmarshalAttributes(attributes map[string]Attribute, m interface{}) error {
 	return nil
 }
 
+var timeType = reflect.TypeOf(time.Time{})
+
 type attributeBuilder struct {
 	buffer []Attribute
 }
@@ -72,6 +85,23 @@ func (builder *attributeBuilder) Push(attribute *Attribute) {
 	builder.buffer = append(builder.buffer, *attribute)
 }
 
+// unmarshallTTLAttribute reads the epoch seconds of a Time to Live
+// attribute back into a time.Time field.
+func unmarshallTTLAttribute(a *Attribute, v reflect.Value) error {
+	n, err := strconv.ParseInt(a.Value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("UnmarshalTypeError (ttl) %#v: %#v", a.Value, err)
+	}
+	t := reflect.ValueOf(time.Unix(n, 0).UTC())
+	if v.Kind() == reflect.Ptr {
+		ptr := reflect.New(timeType)
+		ptr.Elem().Set(t)
+		t = ptr
+	}
+	v.Set(t)
+	return nil
+}
+
 func unmarshallAttribute(a *Attribute, v reflect.Value) error {
 	switch v.Kind() {
 	case reflect.Bool:
@@ -274,6 +304,29 @@ func (e *attributeBuilder) reflectToDynamoDBAttribute(name string, v reflect.Val
 	return nil
 }
 
+// reflectToTTLAttribute writes a field tagged with the "ttl" option as the
+// epoch seconds number DynamoDB's Time to Live expects. A zero time.Time is
+// left out so the item never expires.
+func (e *attributeBuilder) reflectToTTLAttribute(name string, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if !t.IsZero() {
+			e.Push(NewNumericAttribute(name, strconv.FormatInt(t.Unix(), 10)))
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.reflectToDynamoDBAttribute(name, v)
+	}
+	return fmt.Errorf("UnsupportedTypeError (ttl) %#v", v.Type())
+}
+
 func numericReflectedValueString(v reflect.Value) (string, error) {
 	switch v.Kind() {
 	case reflect.Bool:
@@ -352,6 +405,7 @@ type field struct {
 	typ       reflect.Type
 	omitEmpty bool
 	quoted    bool
+	ttl       bool // written as epoch seconds for DynamoDB Time to Live
 }
 
 // byName sorts field by name, breaking ties with depth,
@@ -508,7 +562,7 @@ func typeFields(t reflect.Type) []field {
 						name = sf.Name
 					}
 					fields = append(fields, field{name, tagged, index, ft,
-						opts.Contains("omitempty"), opts.Contains("string")})
+						opts.Contains("omitempty"), opts.Contains("string"), opts.Contains("ttl")})
 					if count[f.typ] > 1 {
 						// If there were multiple instances, add a second,
 						// so that the annihilation code will see a duplicate.
@@ -281,3 +281,60 @@ func (s *MarshallerSuite) TestUnmarshalEmptySets(c *gocheck.C) {
 	expected := testObjectWithNilSets()
 	c.Check(testObj, gocheck.DeepEquals, expected)
 }
+
+type TestStructTTL struct {
+	Name       string
+	ExpiresAt  time.Time  `json:"expires_at,ttl"`
+	PurgeAt    *time.Time `json:",ttl"`
+	NeverUsed  time.Time  `json:",ttl"`
+	ExpiresRaw int64      `json:",ttl"`
+}
+
+func testObjectTTL() *TestStructTTL {
+	expires := time.Unix(1700000000, 0).UTC()
+	purge := time.Unix(1800000000, 0).UTC()
+	return &TestStructTTL{
+		Name:       "session",
+		ExpiresAt:  expires,
+		PurgeAt:    &purge,
+		ExpiresRaw: 1900000000,
+	}
+}
+
+func testAttrsTTL() []ddbomb.Attribute {
+	return []ddbomb.Attribute{
+		ddbomb.Attribute{Type: "S", Name: "Name", Value: "session", SetValues: []string(nil)},
+		ddbomb.Attribute{Type: "N", Name: "expires_at", Value: "1700000000", SetValues: []string(nil)},
+		ddbomb.Attribute{Type: "N", Name: "PurgeAt", Value: "1800000000", SetValues: []string(nil)},
+		ddbomb.Attribute{Type: "N", Name: "ExpiresRaw", Value: "1900000000", SetValues: []string(nil)},
+	}
+}
+
+func (s *MarshallerSuite) TestMarshalTTL(c *gocheck.C) {
+	testObj := testObjectTTL()
+	attrs, err := ddbomb.MarshalAttributes(testObj)
+	if err != nil {
+		c.Errorf("Error from ddbomb.MarshalAttributes: %#v", err)
+	}
+
+	expected := testAttrsTTL()
+	c.Check(attrs, gocheck.DeepEquals, expected)
+}
+
+func (s *MarshallerSuite) TestUnmarshalTTL(c *gocheck.C) {
+	testObj := &TestStructTTL{}
+
+	attrMap := map[string]ddbomb.Attribute{}
+	attrs := testAttrsTTL()
+	for i, _ := range attrs {
+		attrMap[attrs[i].Name] = attrs[i]
+	}
+
+	err := ddbomb.UnmarshalAttributes(attrMap, testObj)
+	if err != nil {
+		c.Fatalf("Error from ddbomb.UnmarshalAttributes: %#v (Built: %#v)", err, testObj)
+	}
+
+	expected := testObjectTTL()
+	c.Check(testObj, gocheck.DeepEquals, expected)
+}
@@ -0,0 +1,16 @@
+package ddbomb
+
+// Handler sends one operation, such as "PutItem", with its query and
+// returns the raw JSON response.
+type Handler func(operation string, query *Query) ([]byte, error)
+
+// Middleware wraps the Handler of a Server to observe or alter every
+// operation: it may change the query before calling next, change what next
+// returns, or answer without calling next at all.
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the chain run around every operation of s. The
+// first middleware added is the outermost one.
+func (s *Server) Use(middleware ...Middleware) {
+	s.Middleware = append(s.Middleware, middleware...)
+}
@@ -0,0 +1,116 @@
+package ddbomb_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type MiddlewareSuite struct{}
+
+var _ = gocheck.Suite(&MiddlewareSuite{})
+
+func tablePrefix(prefix string) ddbomb.Middleware {
+	return func(next ddbomb.Handler) ddbomb.Handler {
+		return func(operation string, query *ddbomb.Query) ([]byte, error) {
+			if name, ok := query.Param("TableName"); ok {
+				query.SetParam("TableName", prefix+name.(string))
+			}
+			return next(operation, query)
+		}
+	}
+}
+
+func trace(name string, events *[]string) ddbomb.Middleware {
+	return func(next ddbomb.Handler) ddbomb.Handler {
+		return func(operation string, query *ddbomb.Query) ([]byte, error) {
+			*events = append(*events, name+" before "+operation)
+			response, err := next(operation, query)
+			*events = append(*events, name+" after "+operation)
+			return response, err
+		}
+	}
+}
+
+func (s *MiddlewareSuite) TestChainOrderAndRequestMutation(c *gocheck.C) {
+	var requests, events []string
+	ts, server := newTestServer(recordTargets(cannedHandler(
+		`{"Table": {"TableName": "staging-sites", "TableStatus": "ACTIVE"}}`,
+	), &requests))
+	defer ts.Close()
+
+	server.Use(trace("outer", &events), tablePrefix("staging-"), trace("inner", &events))
+
+	desc, err := server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(desc.TableName, gocheck.Equals, "staging-sites")
+	c.Check(requests, gocheck.DeepEquals, []string{
+		`DynamoDB_20120810.DescribeTable {"TableName":"staging-sites"}`,
+	})
+	c.Check(events, gocheck.DeepEquals, []string{
+		"outer before DescribeTable",
+		"inner before DescribeTable",
+		"inner after DescribeTable",
+		"outer after DescribeTable",
+	})
+}
+
+func (s *MiddlewareSuite) TestMutationDoesntLeakIntoNextPage(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(cannedHandler(
+		`{"Count": 2, "LastEvaluatedKey": {"Domain": {"S": "example.com"}}}`,
+		`{"Count": 1}`,
+	), &requests))
+	defer ts.Close()
+
+	server.Use(tablePrefix("staging-"))
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	count, err := table.CountScanWithOptions(nil)
+	c.Assert(err, gocheck.IsNil)
+	c.Check(count, gocheck.Equals, int64(3))
+	c.Check(requests, gocheck.DeepEquals, []string{
+		`DynamoDB_20120810.Scan {"Select":"COUNT","TableName":"staging-sites"}`,
+		`DynamoDB_20120810.Scan {"ExclusiveStartKey":{"Domain":{"S":"example.com"}},"Select":"COUNT","TableName":"staging-sites"}`,
+	})
+}
+
+func (s *MiddlewareSuite) TestFaultInjection(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(cannedHandler(`{}`), &requests))
+	defer ts.Close()
+
+	injected := errors.New("injected")
+	server.Use(func(next ddbomb.Handler) ddbomb.Handler {
+		return func(operation string, query *ddbomb.Query) ([]byte, error) {
+			if operation == "PutItem" {
+				return nil, injected
+			}
+			return next(operation, query)
+		}
+	})
+
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	_, err := table.PutItem("example.com", "", []ddbomb.Attribute{*ddbomb.NewStringAttribute("Owner", "ann")})
+	c.Check(err, gocheck.Equals, injected)
+	_, err = table.DeleteItem(&ddbomb.Key{HashKey: "example.com"})
+	c.Check(err, gocheck.IsNil)
+
+	c.Assert(requests, gocheck.HasLen, 1)
+	c.Check(strings.HasPrefix(requests[0], "DynamoDB_20120810.DeleteItem "), gocheck.Equals, true)
+}
+
+func (s *MiddlewareSuite) TestStreamsOperationName(c *gocheck.C) {
+	var requests, events []string
+	ts, server := newTestServer(recordTargets(cannedHandler(`{"Streams": []}`), &requests))
+	defer ts.Close()
+
+	streams := ddbomb.NewStreamsServer(server)
+	streams.Server.Use(trace("t", &events))
+	_, err := streams.ListStreams("sites")
+	c.Assert(err, gocheck.IsNil)
+
+	c.Check(events, gocheck.DeepEquals, []string{"t before ListStreams", "t after ListStreams"})
+	c.Check(strings.HasPrefix(requests[0], "DynamoDBStreams_20120810.ListStreams "), gocheck.Equals, true)
+}
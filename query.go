@@ -1,8 +1,6 @@
 package ddbomb
 
 import (
-	"errors"
-	"fmt"
 	simplejson "github.com/bitly/go-simplejson"
 )
 
@@ -68,8 +66,7 @@ func runQuery(q *Query, t *Table) ([]map[string]Attribute, error) {
 
 	itemCount, err := json.Get("Count").Int()
 	if err != nil {
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
+		return nil, unexpectedResponse(jsonResponse)
 	}
 
 	results := make([]map[string]Attribute, itemCount)
@@ -77,10 +74,9 @@ func runQuery(q *Query, t *Table) ([]map[string]Attribute, error) {
 	for i, _ := range results {
 		item, err := json.Get("Items").GetIndex(i).Map()
 		if err != nil {
-			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-			return nil, errors.New(message)
+			return nil, unexpectedResponse(jsonResponse)
 		}
-		results[i] = parseAttributes(item)
+		results[i] = t.Server.parseAttributes(item)
 	}
 	return results, nil
 }
@@ -1,20 +1,31 @@
 package ddbomb
 
 import (
+	"context"
 	"encoding/json"
+	"sort"
+	"time"
 )
 
-type msi map[string]interface{}
+type msi = map[string]interface{}
 type Query struct {
 	buffer msi
+	ctx    context.Context
+	state  *queryState // shared with the copies made by WithContext
+}
+
+// queryState is what sending the query found out.
+type queryState struct {
+	retries   int
+	throttles int
 }
 
 func NewEmptyQuery() *Query {
-	return &Query{msi{}}
+	return &Query{buffer: msi{}, state: &queryState{}}
 }
 
 func NewQuery(t *Table) *Query {
-	q := &Query{msi{}}
+	q := NewEmptyQuery()
 	q.addTable(t)
 	return q
 }
@@ -79,8 +90,9 @@ func (q *Query) AddWriteRequestItems(tableItems map[*Table]map[string][][]Attrib
 		for table, itemActions := range tableItems {
 			out[table.Name] = func() interface{} {
 				out2 := []interface{}{}
-				for action, items := range itemActions {
-					for _, attributes := range items {
+				// Walk the actions in a fixed order so the request is reproducible.
+				for _, action := range []string{"Put", "Delete"} {
+					for _, attributes := range itemActions[action] {
 						Item_or_Key := map[bool]string{true: "Item", false: "Key"}[action == "Put"]
 						out2 = append(out2, msi{action + "Request": msi{Item_or_Key: attributeList(attributes)}})
 					}
@@ -105,20 +117,28 @@ func (q *Query) AddCreateRequestTable(description TableDescriptionT) {
 	b["AttributeDefinitions"] = attDefs
 	b["KeySchema"] = description.KeySchema
 	b["TableName"] = description.TableName
-	b["ProvisionedThroughput"] = msi{
-		"ReadCapacityUnits":  int(description.ProvisionedThroughput.ReadCapacityUnits),
-		"WriteCapacityUnits": int(description.ProvisionedThroughput.WriteCapacityUnits),
+
+	// On-demand tables and their indexes must not carry a throughput.
+	provisioned := description.BillingMode != BILLING_PAY_PER_REQUEST
+	if description.BillingMode != "" {
+		b["BillingMode"] = description.BillingMode
+	}
+	if provisioned {
+		b["ProvisionedThroughput"] = provisionedThroughput(description.ProvisionedThroughput)
 	}
 
 	globalSecondaryIndexes := []interface{}{}
 
 	for _, ind := range description.GlobalSecondaryIndexes {
-		globalSecondaryIndexes = append(globalSecondaryIndexes, msi{
-			"IndexName":             ind.IndexName,
-			"KeySchema":             ind.KeySchema,
-			"Projection":            ind.Projection,
-			"ProvisionedThroughput": ind.ProvisionedThroughput,
-		})
+		gsi := msi{
+			"IndexName":  ind.IndexName,
+			"KeySchema":  ind.KeySchema,
+			"Projection": ind.Projection,
+		}
+		if provisioned {
+			gsi["ProvisionedThroughput"] = provisionedThroughput(ind.ProvisionedThroughput)
+		}
+		globalSecondaryIndexes = append(globalSecondaryIndexes, gsi)
 	}
 
 	if len(globalSecondaryIndexes) > 0 {
@@ -138,6 +158,164 @@ func (q *Query) AddCreateRequestTable(description TableDescriptionT) {
 	if len(localSecondaryIndexes) > 0 {
 		b["LocalSecondaryIndexes"] = localSecondaryIndexes
 	}
+
+	if len(description.Tags) > 0 {
+		q.AddTags(description.Tags)
+	}
+}
+
+func (q *Query) AddUpdateRequestTable(update UpdateTableT) {
+	b := q.buffer
+	b["TableName"] = update.TableName
+
+	if len(update.AttributeDefinitions) > 0 {
+		attDefs := []interface{}{}
+		for _, attr := range update.AttributeDefinitions {
+			attDefs = append(attDefs, msi{
+				"AttributeName": attr.Name,
+				"AttributeType": attr.Type,
+			})
+		}
+		b["AttributeDefinitions"] = attDefs
+	}
+
+	if update.BillingMode != "" {
+		b["BillingMode"] = update.BillingMode
+	}
+
+	if update.ProvisionedThroughput != nil {
+		b["ProvisionedThroughput"] = provisionedThroughput(*update.ProvisionedThroughput)
+	}
+
+	indexUpdates := []interface{}{}
+
+	for _, u := range update.GlobalSecondaryIndexUpdates {
+		switch {
+		case u.Create != nil:
+			create := msi{
+				"IndexName":  u.Create.IndexName,
+				"KeySchema":  u.Create.KeySchema,
+				"Projection": u.Create.Projection,
+			}
+			// Indexes of on-demand tables must not carry a throughput, and
+			// the billing mode of the table is unknown when update doesn't
+			// change it, so a zero throughput is left out.
+			throughput := u.Create.ProvisionedThroughput
+			if update.BillingMode != BILLING_PAY_PER_REQUEST && (throughput.ReadCapacityUnits > 0 || throughput.WriteCapacityUnits > 0) {
+				create["ProvisionedThroughput"] = provisionedThroughput(throughput)
+			}
+			indexUpdates = append(indexUpdates, msi{"Create": create})
+		case u.Update != nil:
+			indexUpdates = append(indexUpdates, msi{"Update": msi{
+				"IndexName":             u.Update.IndexName,
+				"ProvisionedThroughput": provisionedThroughput(u.Update.ProvisionedThroughput),
+			}})
+		case u.Delete != nil:
+			indexUpdates = append(indexUpdates, msi{"Delete": msi{
+				"IndexName": u.Delete.IndexName,
+			}})
+		}
+	}
+
+	if len(indexUpdates) > 0 {
+		b["GlobalSecondaryIndexUpdates"] = indexUpdates
+	}
+
+	if update.StreamSpecification != nil {
+		b["StreamSpecification"] = update.StreamSpecification
+	}
+}
+
+func provisionedThroughput(throughput ProvisionedThroughputT) msi {
+	return msi{
+		"ReadCapacityUnits":  int(throughput.ReadCapacityUnits),
+		"WriteCapacityUnits": int(throughput.WriteCapacityUnits),
+	}
+}
+
+func (q *Query) AddTimeToLiveSpecification(attributeName string, enabled bool) {
+	q.buffer["TimeToLiveSpecification"] = msi{
+		"AttributeName": attributeName,
+		"Enabled":       enabled,
+	}
+}
+
+func (q *Query) AddBackupName(backupName string) {
+	q.buffer["BackupName"] = backupName
+}
+
+func (q *Query) AddBackupArn(backupArn string) {
+	q.buffer["BackupArn"] = backupArn
+}
+
+func (q *Query) AddExclusiveStartBackupArn(backupArn string) {
+	q.buffer["ExclusiveStartBackupArn"] = backupArn
+}
+
+func (q *Query) AddSourceTableName(tableName string) {
+	q.buffer["SourceTableName"] = tableName
+}
+
+func (q *Query) AddTargetTableName(tableName string) {
+	q.buffer["TargetTableName"] = tableName
+}
+
+// AddRestoreDateTime sets the point in time to restore to. A zero time asks
+// for the latest restorable time instead.
+func (q *Query) AddRestoreDateTime(restoreDateTime time.Time) {
+	if restoreDateTime.IsZero() {
+		q.buffer["UseLatestRestorableTime"] = true
+		return
+	}
+	q.buffer["RestoreDateTime"] = EpochTime{restoreDateTime}
+}
+
+func (q *Query) AddPointInTimeRecoverySpecification(enabled bool) {
+	q.buffer["PointInTimeRecoverySpecification"] = msi{
+		"PointInTimeRecoveryEnabled": enabled,
+	}
+}
+
+func (q *Query) AddResourceArn(resourceArn string) {
+	q.buffer["ResourceArn"] = resourceArn
+}
+
+func (q *Query) AddTags(tags []TagT) {
+	q.buffer["Tags"] = tags
+}
+
+func (q *Query) AddTagKeys(tagKeys []string) {
+	q.buffer["TagKeys"] = tagKeys
+}
+
+func (q *Query) AddNextToken(nextToken string) {
+	q.buffer["NextToken"] = nextToken
+}
+
+func (q *Query) AddStreamArn(streamArn string) {
+	q.buffer["StreamArn"] = streamArn
+}
+
+func (q *Query) AddExclusiveStartStreamArn(streamArn string) {
+	q.buffer["ExclusiveStartStreamArn"] = streamArn
+}
+
+func (q *Query) AddExclusiveStartShardId(shardId string) {
+	q.buffer["ExclusiveStartShardId"] = shardId
+}
+
+// AddShardIteratorPosition sets where GetShardIterator starts reading.
+// sequenceNumber is only sent for the AT_ and AFTER_SEQUENCE_NUMBER types.
+func (q *Query) AddShardIteratorPosition(shardId string, iteratorType ShardIteratorType, sequenceNumber string) {
+	q.buffer["ShardId"] = shardId
+	q.buffer["ShardIteratorType"] = iteratorType
+	if sequenceNumber != "" {
+		q.buffer["SequenceNumber"] = sequenceNumber
+	}
+}
+
+func (q *Query) AddShardIterator(shardIterator string) {
+	q.buffer["ShardIterator"] = shardIterator
 }
 
 func (q *Query) AddDeleteRequestTable(description TableDescriptionT) {
@@ -145,6 +323,10 @@ func (q *Query) AddDeleteRequestTable(description TableDescriptionT) {
 	b["TableName"] = description.TableName
 }
 
+func (q *Query) AddExclusiveStartTableName(tableName string) {
+	q.buffer["ExclusiveStartTableName"] = tableName
+}
+
 func (q *Query) AddKeyConditions(comparisons []AttributeComparison) {
 	q.buffer["KeyConditions"] = buildComparisons(comparisons)
 }
@@ -161,9 +343,9 @@ func (q *Query) AddIndex(value string) {
 }
 
 /*
-   "ScanFilter":{
-       "AttributeName1":{"AttributeValueList":[{"S":"AttributeValue"}],"ComparisonOperator":"EQ"}
-   },
+	"ScanFilter":{
+	    "AttributeName1":{"AttributeValueList":[{"S":"AttributeValue"}],"ComparisonOperator":"EQ"}
+	},
 */
 func (q *Query) AddScanFilter(comparisons []AttributeComparison) {
 	q.buffer["ScanFilter"] = buildComparisons(comparisons)
@@ -174,6 +356,37 @@ func (q *Query) AddParallelScanConfiguration(segment int, totalSegments int) {
 	q.buffer["TotalSegments"] = totalSegments
 }
 
+// AddScanOptions sets every scan parameter present in opts. Zero values are
+// left out of the request so DynamoDB applies its own defaults.
+func (q *Query) AddScanOptions(opts *ScanOptions) {
+	if opts == nil {
+		return
+	}
+	if len(opts.Filter) > 0 {
+		q.AddScanFilter(opts.Filter)
+	}
+	if opts.Limit > 0 {
+		q.AddLimit(opts.Limit)
+	}
+	if opts.IndexName != "" {
+		q.AddIndex(opts.IndexName)
+	}
+	if opts.TotalSegments > 0 {
+		q.AddParallelScanConfiguration(opts.Segment, opts.TotalSegments)
+	}
+	if opts.ExclusiveStartKey != nil {
+		q.AddExclusiveStartKey(opts.ExclusiveStartKey)
+	}
+	q.AddAttributesToGet(opts.AttributesToGet)
+	q.ConsistentRead(opts.ConsistentRead)
+}
+
+// AddExclusiveStartKey continues a Scan or Query from the LastEvaluatedKey
+// returned by a previous page. The key is passed through in wire format.
+func (q *Query) AddExclusiveStartKey(key map[string]interface{}) {
+	q.buffer["ExclusiveStartKey"] = key
+}
+
 func buildComparisons(comparisons []AttributeComparison) msi {
 	out := msi{}
 
@@ -248,6 +461,72 @@ func (q *Query) addTableByName(tableName string) {
 	q.buffer["TableName"] = tableName
 }
 
+// Param returns a request parameter, such as "TableName", as the Add
+// methods stored it. Nested objects are map[string]interface{}.
+func (q *Query) Param(name string) (interface{}, bool) {
+	value, ok := q.buffer[name]
+	return value, ok
+}
+
+// SetParam replaces a request parameter, removing it when value is nil.
+func (q *Query) SetParam(name string, value interface{}) {
+	if value == nil {
+		delete(q.buffer, name)
+		return
+	}
+	q.buffer[name] = value
+}
+
+// TableNames returns the tables the query works on: its TableName, or the
+// tables of a batch request in order.
+func (q *Query) TableNames() []string {
+	if name, ok := q.buffer["TableName"].(string); ok {
+		return []string{name}
+	}
+
+	tables, _ := q.buffer["RequestItems"].(msi)
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Context returns the context the query is sent under.
+func (q *Query) Context() context.Context {
+	if q.ctx == nil {
+		return context.Background()
+	}
+	return q.ctx
+}
+
+// WithContext returns a copy of q sent under ctx. Middleware use it to pass
+// a derived context, such as one holding a span, down the chain.
+func (q *Query) WithContext(ctx context.Context) *Query {
+	q2 := *q
+	q2.ctx = ctx
+	return &q2
+}
+
+// Retries returns how many times the query was resent after a retryable
+// error, see Server.MaxRetries.
+func (q *Query) Retries() int {
+	if q.state == nil {
+		return 0
+	}
+	return q.state.retries
+}
+
+// Throttles returns how many times sending the query was throttled,
+// whether or not a retry then succeeded.
+func (q *Query) Throttles() int {
+	if q.state == nil {
+		return 0
+	}
+	return q.state.throttles
+}
+
 func (q *Query) String() string {
 	bytes, _ := json.Marshal(q.buffer)
 	return string(bytes)
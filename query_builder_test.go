@@ -2,7 +2,6 @@ package ddbomb_test
 
 import (
 	simplejson "github.com/bitly/go-simplejson"
-	"github.com/crowdmob/goamz/aws"
 	"github.com/ryansb/dynamodbomb"
 	"launchpad.net/gocheck"
 )
@@ -14,8 +13,8 @@ type QueryBuilderSuite struct {
 var _ = gocheck.Suite(&QueryBuilderSuite{})
 
 func (s *QueryBuilderSuite) SetUpSuite(c *gocheck.C) {
-	auth := &aws.Auth{AccessKey: "", SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
-	s.server = &ddbomb.Server{*auth, aws.USEast}
+	creds := ddbomb.NewStaticCredentials("", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "")
+	s.server = &ddbomb.Server{Credentials: creds, Region: ddbomb.NewRegion("us-east-1")}
 }
 
 func (s *QueryBuilderSuite) TestEmptyQuery(c *gocheck.C) {
@@ -378,3 +377,313 @@ func (s *QueryBuilderSuite) TestAddKeyConditions(c *gocheck.C) {
 	}
 	c.Check(queryJson, gocheck.DeepEquals, expectedJson)
 }
+
+func (s *QueryBuilderSuite) TestAddScanOptions(c *gocheck.C) {
+	primary := ddbomb.NewStringAttribute("domain", "")
+	key := ddbomb.PrimaryKey{primary, nil}
+	table := s.server.NewTable("sites", key)
+
+	q := ddbomb.NewQuery(table)
+	q.AddScanOptions(&ddbomb.ScanOptions{
+		Filter: []ddbomb.AttributeComparison{
+			*ddbomb.NewStringAttributeComparison("path", "BEGINS_WITH", "/blog"),
+		},
+		Limit:           25,
+		ConsistentRead:  true,
+		IndexName:       "path-index",
+		AttributesToGet: []string{"domain", "path"},
+		Segment:         1,
+		TotalSegments:   4,
+	})
+	q.AddExclusiveStartKey(map[string]interface{}{
+		"domain": map[string]interface{}{"S": "example.com"},
+	})
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+{
+  "AttributesToGet": ["domain", "path"],
+  "ConsistentRead": "true",
+  "ExclusiveStartKey": {
+    "domain": {
+      "S": "example.com"
+    }
+  },
+  "IndexName": "path-index",
+  "Limit": 25,
+  "ScanFilter": {
+    "path": {
+      "AttributeValueList": [
+        {
+          "S": "/blog"
+        }
+      ],
+      "ComparisonOperator": "BEGINS_WITH"
+    }
+  },
+  "Segment": 1,
+  "TableName": "sites",
+  "TotalSegments": 4
+}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, gocheck.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddUpdateRequestTable(c *gocheck.C) {
+	q := ddbomb.NewEmptyQuery()
+	q.AddUpdateRequestTable(ddbomb.UpdateTableT{
+		TableName: "sites",
+		AttributeDefinitions: []ddbomb.AttributeDefinitionT{
+			ddbomb.AttributeDefinitionT{"path", "S"},
+		},
+		ProvisionedThroughput: &ddbomb.ProvisionedThroughputT{
+			ReadCapacityUnits:  10,
+			WriteCapacityUnits: 5,
+		},
+		GlobalSecondaryIndexUpdates: []ddbomb.GlobalSecondaryIndexUpdateT{
+			{Create: &ddbomb.GlobalSecondaryIndexT{
+				IndexName: "path-index",
+				KeySchema: []ddbomb.KeySchemaT{
+					ddbomb.KeySchemaT{"path", "HASH"},
+				},
+				Projection: ddbomb.ProjectionT{ProjectionType: "KEYS_ONLY"},
+				ProvisionedThroughput: ddbomb.ProvisionedThroughputT{
+					ReadCapacityUnits:  2,
+					WriteCapacityUnits: 1,
+				},
+			}},
+			{Update: &ddbomb.GlobalSecondaryIndexT{
+				IndexName: "owner-index",
+				ProvisionedThroughput: ddbomb.ProvisionedThroughputT{
+					ReadCapacityUnits:  3,
+					WriteCapacityUnits: 3,
+				},
+			}},
+			{Delete: &ddbomb.GlobalSecondaryIndexT{IndexName: "old-index"}},
+		},
+		StreamSpecification: &ddbomb.StreamSpecificationT{
+			StreamEnabled:  true,
+			StreamViewType: ddbomb.STREAM_NEW_AND_OLD_IMAGES,
+		},
+	})
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+{
+  "AttributeDefinitions": [
+    {
+      "AttributeName": "path",
+      "AttributeType": "S"
+    }
+  ],
+  "GlobalSecondaryIndexUpdates": [
+    {
+      "Create": {
+        "IndexName": "path-index",
+        "KeySchema": [
+          {
+            "AttributeName": "path",
+            "KeyType": "HASH"
+          }
+        ],
+        "Projection": {
+          "NonKeyAttributes": null,
+          "ProjectionType": "KEYS_ONLY"
+        },
+        "ProvisionedThroughput": {
+          "ReadCapacityUnits": 2,
+          "WriteCapacityUnits": 1
+        }
+      }
+    },
+    {
+      "Update": {
+        "IndexName": "owner-index",
+        "ProvisionedThroughput": {
+          "ReadCapacityUnits": 3,
+          "WriteCapacityUnits": 3
+        }
+      }
+    },
+    {
+      "Delete": {
+        "IndexName": "old-index"
+      }
+    }
+  ],
+  "ProvisionedThroughput": {
+    "ReadCapacityUnits": 10,
+    "WriteCapacityUnits": 5
+  },
+  "StreamSpecification": {
+    "StreamEnabled": true,
+    "StreamViewType": "NEW_AND_OLD_IMAGES"
+  },
+  "TableName": "sites"
+}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, gocheck.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddUpdateRequestTableIndexOnDemand(c *gocheck.C) {
+	q := ddbomb.NewEmptyQuery()
+	q.AddUpdateRequestTable(ddbomb.UpdateTableT{
+		TableName: "sites",
+		AttributeDefinitions: []ddbomb.AttributeDefinitionT{
+			ddbomb.AttributeDefinitionT{"path", "S"},
+		},
+		GlobalSecondaryIndexUpdates: []ddbomb.GlobalSecondaryIndexUpdateT{
+			{Create: &ddbomb.GlobalSecondaryIndexT{
+				IndexName: "path-index",
+				KeySchema: []ddbomb.KeySchemaT{
+					ddbomb.KeySchemaT{"path", "HASH"},
+				},
+				Projection: ddbomb.ProjectionT{ProjectionType: "KEYS_ONLY"},
+			}},
+		},
+	})
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+{
+  "AttributeDefinitions": [
+    {
+      "AttributeName": "path",
+      "AttributeType": "S"
+    }
+  ],
+  "GlobalSecondaryIndexUpdates": [
+    {
+      "Create": {
+        "IndexName": "path-index",
+        "KeySchema": [
+          {
+            "AttributeName": "path",
+            "KeyType": "HASH"
+          }
+        ],
+        "Projection": {
+          "NonKeyAttributes": null,
+          "ProjectionType": "KEYS_ONLY"
+        }
+      }
+    }
+  ],
+  "TableName": "sites"
+}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, gocheck.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddCreateRequestTablePayPerRequest(c *gocheck.C) {
+	description := ddbomb.TableDescriptionT{
+		TableName:   "sites",
+		BillingMode: ddbomb.BILLING_PAY_PER_REQUEST,
+		AttributeDefinitions: []ddbomb.AttributeDefinitionT{
+			ddbomb.AttributeDefinitionT{"domain", "S"},
+			ddbomb.AttributeDefinitionT{"path", "S"},
+		},
+		KeySchema: []ddbomb.KeySchemaT{
+			ddbomb.KeySchemaT{"domain", "HASH"},
+		},
+		GlobalSecondaryIndexes: []ddbomb.GlobalSecondaryIndexT{
+			{
+				IndexName: "path-index",
+				KeySchema: []ddbomb.KeySchemaT{
+					ddbomb.KeySchemaT{"path", "HASH"},
+				},
+				Projection: ddbomb.ProjectionT{ProjectionType: "ALL"},
+			},
+		},
+	}
+
+	q := ddbomb.NewEmptyQuery()
+	q.AddCreateRequestTable(description)
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+{
+  "AttributeDefinitions": [
+    {
+      "AttributeName": "domain",
+      "AttributeType": "S"
+    },
+    {
+      "AttributeName": "path",
+      "AttributeType": "S"
+    }
+  ],
+  "BillingMode": "PAY_PER_REQUEST",
+  "GlobalSecondaryIndexes": [
+    {
+      "IndexName": "path-index",
+      "KeySchema": [
+        {
+          "AttributeName": "path",
+          "KeyType": "HASH"
+        }
+      ],
+      "Projection": {
+        "NonKeyAttributes": null,
+        "ProjectionType": "ALL"
+      }
+    }
+  ],
+  "KeySchema": [
+    {
+      "AttributeName": "domain",
+      "KeyType": "HASH"
+    }
+  ],
+  "TableName": "sites"
+}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, gocheck.DeepEquals, expectedJson)
+
+	// Provisioned tables keep their throughput on the table and every index.
+	description.BillingMode = ""
+	description.ProvisionedThroughput = ddbomb.ProvisionedThroughputT{ReadCapacityUnits: 5, WriteCapacityUnits: 5}
+	description.GlobalSecondaryIndexes[0].ProvisionedThroughput = ddbomb.ProvisionedThroughputT{ReadCapacityUnits: 1, WriteCapacityUnits: 1}
+
+	q = ddbomb.NewEmptyQuery()
+	q.AddCreateRequestTable(description)
+	queryJson, err = simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	_, ok := queryJson.CheckGet("BillingMode")
+	c.Check(ok, gocheck.Equals, false)
+	c.Check(queryJson.GetPath("ProvisionedThroughput", "ReadCapacityUnits").MustInt(), gocheck.Equals, 5)
+	gsiThroughput := queryJson.Get("GlobalSecondaryIndexes").GetIndex(0).Get("ProvisionedThroughput")
+	c.Check(gsiThroughput.MustMap(), gocheck.HasLen, 2)
+	c.Check(gsiThroughput.Get("WriteCapacityUnits").MustInt(), gocheck.Equals, 1)
+}
@@ -0,0 +1,241 @@
+package ddbomb
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Operations consuming read and write capacity.
+var (
+	readOperations = map[string]bool{
+		"GetItem": true, "BatchGetItem": true, "Query": true, "Scan": true, "TransactGetItems": true,
+	}
+	writeOperations = map[string]bool{
+		"PutItem": true, "UpdateItem": true, "DeleteItem": true, "BatchWriteItem": true, "TransactWriteItems": true,
+	}
+)
+
+// RateLimiter keeps the capacity used by the operations of a Server under
+// per-table limits, so that a backfill doesn't throttle the table for
+// everyone else. Set it as Server.RateLimiter:
+//
+//	server.RateLimiter = ddbomb.NewRateLimiter()
+//	server.RateLimiter.SetCapacity("sites", 50, 20)
+//
+// Each limited table has a read and a write token bucket, refilled with
+// capacity units every second. An operation waits until its bucket holds
+// the capacity it is expected to consume, which is what the same operation
+// consumed last time, as reported by ConsumedCapacity. The bucket is then
+// charged for what it actually consumed. Tables without limits aren't
+// delayed.
+type RateLimiter struct {
+	// ProvisionedFraction, when positive, limits every table described
+	// with DescribeTable to that fraction of its provisioned throughput.
+	ProvisionedFraction float64
+
+	mu     sync.Mutex
+	tables map[string]*tableLimits
+}
+
+type tableLimits struct {
+	read, write tokenBucket
+
+	// Capacity consumed by the last call of each operation.
+	estimates map[string]float64
+}
+
+// NewRateLimiter returns a RateLimiter without any limits. The zero value
+// is usable too.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{}
+}
+
+// SetCapacity limits table to read and write capacity units per second.
+// Zero leaves that kind of operations unlimited.
+func (l *RateLimiter) SetCapacity(table string, read, write float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.setCapacity(table, read, write, time.Now())
+}
+
+func (l *RateLimiter) setCapacity(table string, read, write float64, now time.Time) {
+	if l.tables == nil {
+		l.tables = map[string]*tableLimits{}
+	}
+	limits := l.tables[table]
+	if limits == nil {
+		limits = &tableLimits{estimates: map[string]float64{}}
+		l.tables[table] = limits
+	}
+	limits.read.setRate(read, now)
+	limits.write.setRate(write, now)
+}
+
+// Capacity returns the read and write capacity units per second table is
+// limited to.
+func (l *RateLimiter) Capacity(table string) (read, write float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limits := l.tables[table]; limits != nil {
+		return limits.read.rate, limits.write.rate
+	}
+	return 0, 0
+}
+
+// bucket returns the bucket of table charged by operation, or nil when it
+// isn't limited.
+func (l *RateLimiter) bucket(table, operation string) (*tableLimits, *tokenBucket) {
+	limits := l.tables[table]
+	if limits == nil {
+		return nil, nil
+	}
+	var b *tokenBucket
+	switch {
+	case readOperations[operation]:
+		b = &limits.read
+	case writeOperations[operation]:
+		b = &limits.write
+	}
+	if b == nil || b.rate <= 0 {
+		return nil, nil
+	}
+	return limits, b
+}
+
+// reserve takes from the buckets of the tables of query the capacity the
+// operation is expected to consume, and returns how long to wait for the
+// buckets to refill along with what was taken, by table.
+func (l *RateLimiter) reserve(operation string, query *Query) (time.Duration, map[string]float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	var reserved map[string]float64
+	for _, table := range query.TableNames() {
+		limits, b := l.bucket(table, operation)
+		if b == nil {
+			continue
+		}
+		estimate, ok := limits.estimates[operation]
+		if !ok {
+			estimate = 1
+		}
+		if w := b.take(estimate, now); w > wait {
+			wait = w
+		}
+		if reserved == nil {
+			reserved = map[string]float64{}
+		}
+		reserved[table] = estimate
+	}
+	return wait, reserved
+}
+
+// observe charges the buckets for the capacity the operation consumed
+// rather than what was reserved, and adapts the limits to the provisioned
+// throughput of described tables.
+func (l *RateLimiter) observe(operation string, reserved map[string]float64, response []byte, failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	consumed := map[string]float64{}
+	for _, c := range ParseConsumedCapacity(response) {
+		consumed[c.TableName] += c.CapacityUnits
+	}
+	for table, estimate := range reserved {
+		limits, b := l.bucket(table, operation)
+		if b == nil {
+			continue
+		}
+		// Failed operations consume nothing, and those whose request sets
+		// ReturnConsumedCapacity to NONE are charged their estimate.
+		if actual, ok := consumed[table]; ok {
+			limits.estimates[operation] = actual
+			b.take(actual-estimate, now)
+		} else if failed {
+			b.take(-estimate, now)
+		}
+	}
+
+	if operation == "DescribeTable" && l.ProvisionedFraction > 0 {
+		var r struct {
+			Table TableDescriptionT
+		}
+		throughput := &r.Table.ProvisionedThroughput
+		if json.Unmarshal(response, &r) == nil && throughput.ReadCapacityUnits > 0 {
+			l.setCapacity(r.Table.TableName,
+				float64(throughput.ReadCapacityUnits)*l.ProvisionedFraction,
+				float64(throughput.WriteCapacityUnits)*l.ProvisionedFraction, now)
+		}
+	}
+}
+
+// tokenBucket holds up to one second worth of capacity units. Its tokens go
+// negative when operations consume more than it holds, delaying the next
+// ones until it refills.
+type tokenBucket struct {
+	rate   float64 // capacity units per second
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) setRate(rate float64, now time.Time) {
+	if b.last.IsZero() {
+		b.tokens = rate
+	}
+	b.refill(now)
+	b.rate = rate
+	if b.tokens > rate {
+		b.tokens = rate
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.rate {
+			b.tokens = b.rate
+		}
+	}
+	b.last = now
+}
+
+// take removes n tokens and returns how long until the bucket is no longer
+// in debt.
+func (b *tokenBucket) take(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens -= n
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// limitRate runs handler once the RateLimiter of s lets the operation
+// through.
+func (s *Server) limitRate(operation string, query *Query, handler Handler) ([]byte, error) {
+	if s.RateLimiter == nil {
+		return handler(operation, query)
+	}
+
+	query.RequestConsumedCapacity(operation)
+	wait, reserved := s.RateLimiter.reserve(operation, query)
+	if wait > 0 {
+		s.log(LOG_DEBUG, "Rate limiting DynamoDB request", "operation", operation, "tables", query.TableNames(), "delay", wait)
+		if err := sleep(query.Context(), wait); err != nil {
+			s.RateLimiter.observe(operation, reserved, nil, true)
+			return nil, err
+		}
+	}
+
+	response, err := handler(operation, query)
+	s.RateLimiter.observe(operation, reserved, response, err != nil)
+	return response, err
+}
@@ -0,0 +1,121 @@
+package ddbomb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"github.com/ryansb/dynamodbomb/ddbombtest"
+	"launchpad.net/gocheck"
+)
+
+type RateLimitSuite struct {
+	ts     *httptest.Server
+	server *ddbomb.Server
+	table  *ddbomb.Table
+}
+
+var _ = gocheck.Suite(&RateLimitSuite{})
+
+func (s *RateLimitSuite) SetUpTest(c *gocheck.C) {
+	s.ts = httptest.NewServer(ddbombtest.NewFake())
+	s.server = ddbomb.NewLocalServer(s.ts.URL)
+	_, err := s.server.CreateTable(ddbomb.TableDescriptionT{
+		TableName:             "sites",
+		AttributeDefinitions:  []ddbomb.AttributeDefinitionT{{Name: "Domain", Type: ddbomb.STRING}},
+		KeySchema:             []ddbomb.KeySchemaT{{AttributeName: "Domain", KeyType: ddbomb.HASH_KEY}},
+		ProvisionedThroughput: ddbomb.ProvisionedThroughputT{ReadCapacityUnits: 10, WriteCapacityUnits: 4},
+	})
+	c.Assert(err, gocheck.IsNil)
+	s.table = s.server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+	s.server.RateLimiter = ddbomb.NewRateLimiter()
+}
+
+func (s *RateLimitSuite) TearDownTest(c *gocheck.C) {
+	s.ts.Close()
+}
+
+func (s *RateLimitSuite) put(c *gocheck.C, n int) time.Duration {
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		_, err := s.table.PutItem(fmt.Sprintf("%d.example.com", i), "", []ddbomb.Attribute{*ddbomb.NewStringAttribute("Owner", "ann")})
+		c.Assert(err, gocheck.IsNil)
+	}
+	return time.Since(start)
+}
+
+func (s *RateLimitSuite) TestWritesAreLimited(c *gocheck.C) {
+	s.server.RateLimiter.SetCapacity("sites", 0, 20)
+
+	// The bucket starts full, then refills with 20 units a second.
+	c.Check(s.put(c, 20) < 200*time.Millisecond, gocheck.Equals, true)
+	c.Check(s.put(c, 5) >= 200*time.Millisecond, gocheck.Equals, true)
+
+	// Reads aren't limited.
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		_, err := s.table.GetItem(&ddbomb.Key{HashKey: "0.example.com"})
+		c.Assert(err, gocheck.IsNil)
+	}
+	c.Check(time.Since(start) < 200*time.Millisecond, gocheck.Equals, true)
+}
+
+func (s *RateLimitSuite) TestUnlimitedTables(c *gocheck.C) {
+	s.server.RateLimiter.SetCapacity("other", 1, 1)
+	c.Check(s.put(c, 10) < 200*time.Millisecond, gocheck.Equals, true)
+}
+
+func (s *RateLimitSuite) TestChargesConsumedCapacity(c *gocheck.C) {
+	s.server.RateLimiter.SetCapacity("sites", 0, 40)
+
+	var items [][]ddbomb.Attribute
+	for i := 0; i < 25; i++ {
+		items = append(items, []ddbomb.Attribute{*ddbomb.NewStringAttribute("Domain", fmt.Sprintf("%d.example.com", i))})
+	}
+	batch := func() {
+		_, err := s.table.BatchWriteItems(map[string][][]ddbomb.Attribute{"Put": items}).Execute()
+		c.Assert(err, gocheck.IsNil)
+	}
+
+	// The first batch is expected to cost a unit, but costs 25, the second
+	// is expected to cost 25 too: 50 units in all, 10 more than the bucket
+	// holds.
+	start := time.Now()
+	batch()
+	batch()
+	c.Check(time.Since(start) >= 200*time.Millisecond, gocheck.Equals, true)
+}
+
+func (s *RateLimitSuite) TestProvisionedFraction(c *gocheck.C) {
+	s.server.RateLimiter.ProvisionedFraction = 0.5
+	read, write := s.server.RateLimiter.Capacity("sites")
+	c.Check([]float64{read, write}, gocheck.DeepEquals, []float64{0, 0})
+
+	_, err := s.server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+	read, write = s.server.RateLimiter.Capacity("sites")
+	c.Check([]float64{read, write}, gocheck.DeepEquals, []float64{5, 2})
+}
+
+func (s *RateLimitSuite) TestQueryIsLeftAlone(c *gocheck.C) {
+	s.server.RateLimiter.SetCapacity("sites", 10, 0)
+
+	q := ddbomb.NewQuery(s.table)
+	before := q.String()
+	_, err := s.table.FetchResults(q)
+	c.Assert(err, gocheck.IsNil)
+	c.Check(q.String(), gocheck.Equals, before)
+}
+
+func (s *RateLimitSuite) TestContextCancelsWait(c *gocheck.C) {
+	s.server.RateLimiter.SetCapacity("sites", 0, 1)
+	s.put(c, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := s.table.WithContext(ctx).PutItem("late.example.com", "", []ddbomb.Attribute{*ddbomb.NewStringAttribute("Owner", "ann")})
+	c.Check(errors.Is(err, context.DeadlineExceeded), gocheck.Equals, true)
+}
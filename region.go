@@ -0,0 +1,116 @@
+package ddbomb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Region is where a Server sends its requests: DynamoDBEndpoint, signed for
+// the region called Name.
+type Region struct {
+	Name             string
+	DynamoDBEndpoint string
+}
+
+// EndpointOptions selects a variant of the regional endpoint.
+type EndpointOptions struct {
+	// FIPS selects the endpoint using FIPS 140-2 validated cryptography.
+	FIPS bool
+
+	// DualStack selects the endpoint reachable over both IPv4 and IPv6.
+	DualStack bool
+}
+
+// partition is a group of regions sharing domain names.
+type partition struct {
+	name            string
+	regions         *regexp.Regexp
+	domain          string
+	dualStackDomain string // empty when dual-stack isn't supported
+}
+
+// The commercial partition comes last, as it takes the regions no other
+// matches.
+var partitions = []partition{
+	{"aws-cn", regexp.MustCompile(`^cn-\w+-\d+$`), "amazonaws.com.cn", "api.amazonwebservices.com.cn"},
+	{"aws-us-gov", regexp.MustCompile(`^us-gov-\w+-\d+$`), "amazonaws.com", "api.aws"},
+	{"aws-iso", regexp.MustCompile(`^us-iso-\w+-\d+$`), "c2s.ic.gov", ""},
+	{"aws-iso-b", regexp.MustCompile(`^us-isob-\w+-\d+$`), "sc2s.sgov.gov", ""},
+	{"aws-iso-e", regexp.MustCompile(`^eu-isoe-\w+-\d+$`), "cloud.adc-e.uk", ""},
+	{"aws-iso-f", regexp.MustCompile(`^us-isof-\w+-\d+$`), "csp.hci.ic.gov", ""},
+	{"aws", regexp.MustCompile(``), "amazonaws.com", "api.aws"},
+}
+
+// ResolveEndpoint returns the region called name, such as "us-east-1", with
+// its DynamoDB endpoint. The FIPS pseudo-regions "fips-us-east-1" and
+// "us-east-1-fips" are accepted too.
+func ResolveEndpoint(name string, opts EndpointOptions) (Region, error) {
+	return resolveEndpoint("dynamodb", name, opts)
+}
+
+// ResolveStreamsEndpoint is like ResolveEndpoint, but returns the DynamoDB
+// Streams endpoint of the region.
+func ResolveStreamsEndpoint(name string, opts EndpointOptions) (Region, error) {
+	return resolveEndpoint("streams.dynamodb", name, opts)
+}
+
+func resolveEndpoint(service, name string, opts EndpointOptions) (Region, error) {
+	if trimmed := strings.TrimSuffix(strings.TrimPrefix(name, "fips-"), "-fips"); trimmed != name {
+		name, opts.FIPS = trimmed, true
+	}
+	if name == "" {
+		return Region{}, errors.New("A region name is required")
+	}
+
+	var p partition
+	for _, p = range partitions {
+		if p.regions.MatchString(name) {
+			break
+		}
+	}
+
+	host, domain := service, p.domain
+	if opts.FIPS {
+		host += "-fips"
+	}
+	if opts.DualStack {
+		if p.dualStackDomain == "" {
+			return Region{}, fmt.Errorf("Dual-stack endpoints aren't available in partition %s", p.name)
+		}
+		domain = p.dualStackDomain
+	}
+	return Region{Name: name, DynamoDBEndpoint: "https://" + host + "." + name + "." + domain}, nil
+}
+
+// NewRegion returns the region called name, such as "us-east-1", with its
+// standard DynamoDB endpoint. See ResolveEndpoint for the other variants.
+func NewRegion(name string) Region {
+	region, _ := ResolveEndpoint(name, EndpointOptions{})
+	return region
+}
+
+// streamsRegion returns the region whose endpoint is the DynamoDB Streams
+// endpoint of the same variant as the DynamoDB endpoint of r. Endpoints the
+// resolver doesn't know, such as DynamoDB Local, serve streams from the
+// same URL and are kept.
+func (r Region) streamsRegion() Region {
+	for _, opts := range []EndpointOptions{{}, {FIPS: true}, {DualStack: true}, {FIPS: true, DualStack: true}} {
+		resolved, err := ResolveEndpoint(r.Name, opts)
+		if err == nil && resolved.DynamoDBEndpoint == r.DynamoDBEndpoint {
+			streams, _ := ResolveStreamsEndpoint(r.Name, opts)
+			return streams
+		}
+	}
+	return r
+}
+
+// signingRegion is the region requests are signed for. Endpoints such as
+// DynamoDB Local accept any, so regions without a name sign for us-east-1.
+func (r Region) signingRegion() string {
+	if r.Name == "" {
+		return "us-east-1"
+	}
+	return r.Name
+}
@@ -0,0 +1,65 @@
+package ddbomb_test
+
+import (
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type RegionSuite struct{}
+
+var _ = gocheck.Suite(&RegionSuite{})
+
+func (s *RegionSuite) TestResolveEndpoint(c *gocheck.C) {
+	for _, t := range []struct {
+		region   string
+		opts     ddbomb.EndpointOptions
+		name     string
+		endpoint string
+	}{
+		{"us-east-1", ddbomb.EndpointOptions{}, "us-east-1", "https://dynamodb.us-east-1.amazonaws.com"},
+		{"us-east-1", ddbomb.EndpointOptions{FIPS: true}, "us-east-1", "https://dynamodb-fips.us-east-1.amazonaws.com"},
+		{"us-east-1", ddbomb.EndpointOptions{DualStack: true}, "us-east-1", "https://dynamodb.us-east-1.api.aws"},
+		{"us-west-2", ddbomb.EndpointOptions{FIPS: true, DualStack: true}, "us-west-2", "https://dynamodb-fips.us-west-2.api.aws"},
+		{"fips-us-east-2", ddbomb.EndpointOptions{}, "us-east-2", "https://dynamodb-fips.us-east-2.amazonaws.com"},
+		{"us-gov-west-1-fips", ddbomb.EndpointOptions{}, "us-gov-west-1", "https://dynamodb-fips.us-gov-west-1.amazonaws.com"},
+		{"cn-north-1", ddbomb.EndpointOptions{}, "cn-north-1", "https://dynamodb.cn-north-1.amazonaws.com.cn"},
+		{"cn-northwest-1", ddbomb.EndpointOptions{DualStack: true}, "cn-northwest-1", "https://dynamodb.cn-northwest-1.api.amazonwebservices.com.cn"},
+		{"us-iso-east-1", ddbomb.EndpointOptions{}, "us-iso-east-1", "https://dynamodb.us-iso-east-1.c2s.ic.gov"},
+		{"us-isob-east-1", ddbomb.EndpointOptions{}, "us-isob-east-1", "https://dynamodb.us-isob-east-1.sc2s.sgov.gov"},
+		{"ap-southeast-7", ddbomb.EndpointOptions{}, "ap-southeast-7", "https://dynamodb.ap-southeast-7.amazonaws.com"},
+	} {
+		region, err := ddbomb.ResolveEndpoint(t.region, t.opts)
+		c.Assert(err, gocheck.IsNil)
+		c.Check(region, gocheck.Equals, ddbomb.Region{Name: t.name, DynamoDBEndpoint: t.endpoint}, gocheck.Commentf("%s %+v", t.region, t.opts))
+	}
+
+	_, err := ddbomb.ResolveEndpoint("us-iso-east-1", ddbomb.EndpointOptions{DualStack: true})
+	c.Check(err, gocheck.ErrorMatches, "Dual-stack endpoints aren't available in partition aws-iso")
+	_, err = ddbomb.ResolveEndpoint("", ddbomb.EndpointOptions{})
+	c.Check(err, gocheck.ErrorMatches, "A region name is required")
+}
+
+func (s *RegionSuite) TestNewServer(c *gocheck.C) {
+	creds := ddbomb.NewStaticCredentials("KEY", "SECRET", "")
+	server, err := ddbomb.NewServer("eu-west-1", ddbomb.WithFIPS(), ddbomb.WithDualStack(), ddbomb.WithCredentials(creds))
+	c.Assert(err, gocheck.IsNil)
+	c.Check(server.Region, gocheck.Equals, ddbomb.Region{Name: "eu-west-1", DynamoDBEndpoint: "https://dynamodb-fips.eu-west-1.api.aws"})
+	c.Check(server.Credentials, gocheck.Equals, creds)
+
+	server, err = ddbomb.NewServer("eu-west-1", ddbomb.WithEndpoint("https://vpce-123.dynamodb.eu-west-1.vpce.amazonaws.com/", ""))
+	c.Assert(err, gocheck.IsNil)
+	c.Check(server.Region, gocheck.Equals, ddbomb.Region{Name: "eu-west-1", DynamoDBEndpoint: "https://vpce-123.dynamodb.eu-west-1.vpce.amazonaws.com"})
+
+	server, err = ddbomb.NewServer("", ddbomb.WithEndpoint("https://proxy.example.com", "ap-south-1"))
+	c.Assert(err, gocheck.IsNil)
+	c.Check(server.Region, gocheck.Equals, ddbomb.Region{Name: "ap-south-1", DynamoDBEndpoint: "https://proxy.example.com"})
+
+	_, err = ddbomb.NewServer("")
+	c.Check(err, gocheck.NotNil)
+}
+
+func (s *RegionSuite) TestNewLocalServer(c *gocheck.C) {
+	server := ddbomb.NewLocalServer("http://localhost:8000/")
+	c.Check(server.Region, gocheck.Equals, ddbomb.Region{Name: "us-east-1", DynamoDBEndpoint: "http://localhost:8000"})
+	c.Check(server.Credentials, gocheck.NotNil)
+}
@@ -0,0 +1,231 @@
+package ddbomb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+type ReplayMode string
+
+const (
+	REPLAY_RECORD   ReplayMode = "record"
+	REPLAY_PLAYBACK            = "playback"
+)
+
+// Response headers that are left out of golden files: they change from
+// one recording to the next or no longer hold once the body is reformatted.
+var unrecordedHeaders = []string{"Date", "Content-Length", "X-Amz-Crc32"}
+
+// Exchange is one request and its response as stored in a golden file.
+// Bodies are kept as JSON so that golden files stay readable and diffable.
+type Exchange struct {
+	Target     string
+	Request    json.RawMessage
+	StatusCode int
+	Header     http.Header `json:",omitempty"`
+	Response   json.RawMessage
+}
+
+// Replayer is an http.RoundTripper that either records every exchange
+// going through it, or answers requests from recorded exchanges without
+// touching the network.
+//
+// Requests are matched on their X-Amz-Target and JSON body only, so the
+// signature, date and token headers may differ from the recording.
+// Identical requests are answered in the order they were recorded, the last
+// answer being repeated once they are used up.
+type Replayer struct {
+	Mode ReplayMode
+	Path string            // golden file
+	Next http.RoundTripper // sends requests while recording; http.DefaultTransport when nil
+
+	mu        sync.Mutex
+	exchanges []Exchange
+	used      []bool
+}
+
+// NewReplayer returns a Replayer for the golden file at path, reading it
+// when playing back.
+func NewReplayer(mode ReplayMode, path string) (*Replayer, error) {
+	r := &Replayer{Mode: mode, Path: path}
+	if mode != REPLAY_PLAYBACK {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.exchanges); err != nil {
+		return nil, err
+	}
+	r.used = make([]bool, len(r.exchanges))
+	return r, nil
+}
+
+// Record makes s keep every exchange it has with DynamoDB. Call Save on the
+// returned Replayer to write them to the golden file at path.
+func (s *Server) Record(path string) *Replayer {
+	r, _ := NewReplayer(REPLAY_RECORD, path)
+	r.Next = s.Transport
+	s.Transport = r
+	return r
+}
+
+// Replay makes s answer every request from the golden file at path.
+func (s *Server) Replay(path string) (*Replayer, error) {
+	r, err := NewReplayer(REPLAY_PLAYBACK, path)
+	if err != nil {
+		return nil, err
+	}
+	s.Transport = r
+	return r, nil
+}
+
+// Exchanges returns the exchanges recorded or loaded so far.
+func (r *Replayer) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+// Save writes the recorded exchanges to the golden file.
+func (r *Replayer) Save() error {
+	data, err := json.MarshalIndent(r.Exchanges(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.Path, append(data, '\n'), 0644)
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	target := req.Header.Get("X-Amz-Target")
+
+	if r.Mode == REPLAY_PLAYBACK {
+		return r.playback(req, target, body)
+	}
+	return r.record(req, target, body)
+}
+
+func (r *Replayer) record(req *http.Request, target string, body []byte) (*http.Response, error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	header := http.Header{}
+	for name, values := range resp.Header {
+		header[name] = values
+	}
+	for _, name := range unrecordedHeaders {
+		header.Del(name)
+	}
+
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, Exchange{
+		Target:     target,
+		Request:    rawJSON(body),
+		StatusCode: resp.StatusCode,
+		Header:     header,
+		Response:   rawJSON(respBody),
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Replayer) playback(req *http.Request, target string, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, ex := range r.exchanges {
+		if ex.Target != target || !sameJSON(ex.Request, body) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("No recorded response for %s %s", target, body)
+	}
+	r.used[match] = true
+
+	ex := r.exchanges[match]
+	header := http.Header{}
+	for name, values := range ex.Header {
+		header[name] = values
+	}
+	respBody := fromRawJSON(ex.Response)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.StatusCode, http.StatusText(ex.StatusCode)),
+		StatusCode:    ex.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// rawJSON keeps a body as JSON, quoting it first when it isn't valid JSON
+// (such as an HTML error page from a proxy).
+func rawJSON(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	quoted, _ := json.Marshal(string(body))
+	return json.RawMessage(quoted)
+}
+
+func fromRawJSON(raw json.RawMessage) []byte {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return []byte(s)
+	}
+	return raw
+}
+
+// sameJSON compares two JSON documents regardless of formatting and key order.
+func sameJSON(a, b []byte) bool {
+	var av, bv interface{}
+	if decodeJSON(a, &av) != nil || decodeJSON(b, &bv) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
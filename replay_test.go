@@ -0,0 +1,79 @@
+package ddbomb_test
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+
+	"github.com/ryansb/dynamodbomb"
+	"github.com/ryansb/dynamodbomb/ddbombtest"
+	"launchpad.net/gocheck"
+)
+
+type ReplaySuite struct{}
+
+var _ = gocheck.Suite(&ReplaySuite{})
+
+var replayTable = ddbomb.TableDescriptionT{
+	TableName:            "sites",
+	BillingMode:          ddbomb.BILLING_PAY_PER_REQUEST,
+	AttributeDefinitions: []ddbomb.AttributeDefinitionT{{Name: "Domain", Type: ddbomb.STRING}},
+	KeySchema:            []ddbomb.KeySchemaT{{AttributeName: "Domain", KeyType: ddbomb.HASH_KEY}},
+}
+
+// exercise runs the same calls against whatever server it's given.
+func exercise(c *gocheck.C, server *ddbomb.Server) {
+	table := server.NewTable("sites", ddbomb.PrimaryKey{KeyAttribute: ddbomb.NewStringAttribute("Domain", "")})
+
+	_, err := server.CreateTable(replayTable)
+	c.Assert(err, gocheck.IsNil)
+	_, err = table.PutItem("example.com", "", []ddbomb.Attribute{*ddbomb.NewStringAttribute("Owner", "ann")})
+	c.Assert(err, gocheck.IsNil)
+
+	item, err := table.GetItem(&ddbomb.Key{HashKey: "example.com"})
+	c.Assert(err, gocheck.IsNil)
+	c.Check(item["Owner"].Value, gocheck.Equals, "ann")
+
+	_, err = server.CreateTable(replayTable)
+	c.Check(err, gocheck.ErrorMatches, "ResourceInUseException: .*")
+}
+
+func (s *ReplaySuite) TestRecordThenReplay(c *gocheck.C) {
+	golden := filepath.Join(c.MkDir(), "sites.json")
+
+	ts := httptest.NewServer(ddbombtest.NewFake())
+	recording := &ddbomb.Server{
+		Credentials: ddbomb.NewStaticCredentials("RECORDING_KEY", "RECORDING_SECRET", ""),
+		Region:      ddbomb.Region{DynamoDBEndpoint: ts.URL},
+	}
+	recorder := recording.Record(golden)
+	exercise(c, recording)
+	ts.Close()
+	c.Assert(recorder.Save(), gocheck.IsNil)
+
+	// Nothing listens on the endpoint and the credentials differ, so every
+	// answer must come from the golden file.
+	replaying := &ddbomb.Server{
+		Credentials: ddbomb.NewStaticCredentials("OTHER_KEY", "OTHER_SECRET", ""),
+		Region:      ddbomb.Region{DynamoDBEndpoint: ts.URL},
+	}
+	replayer, err := replaying.Replay(golden)
+	c.Assert(err, gocheck.IsNil)
+	c.Check(replayer.Exchanges(), gocheck.HasLen, 4)
+	exercise(c, replaying)
+}
+
+func (s *ReplaySuite) TestReplayUnknownRequest(c *gocheck.C) {
+	golden := filepath.Join(c.MkDir(), "empty.json")
+	recorder := (&ddbomb.Server{}).Record(golden)
+	c.Assert(recorder.Save(), gocheck.IsNil)
+
+	server := &ddbomb.Server{
+		Credentials: ddbomb.NewStaticCredentials("DUMMY_KEY", "DUMMY_SECRET", ""),
+		Region:      ddbomb.Region{DynamoDBEndpoint: "http://127.0.0.1:1"},
+	}
+	_, err := server.Replay(golden)
+	c.Assert(err, gocheck.IsNil)
+
+	_, err = server.DescribeTable("sites")
+	c.Check(err, gocheck.ErrorMatches, `.*No recorded response for DynamoDB_20120810.DescribeTable {"TableName":"sites"}`)
+}
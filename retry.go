@@ -0,0 +1,52 @@
+package ddbomb
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	retryBaseDelay = 50 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// retryDelay is an exponential backoff with jitter for the given retry,
+// counting from zero.
+func retryDelay(retry int) time.Duration {
+	delay := retryMaxDelay
+	if retry < 16 && retryBaseDelay<<uint(retry) < retryMaxDelay {
+		delay = retryBaseDelay << uint(retry)
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// sendWithRetries sends the query, resending it up to MaxRetries times
+// while it fails with a retryable error.
+func (s *Server) sendWithRetries(target string, query *Query) ([]byte, error) {
+	for {
+		if s.Throttle != nil {
+			if err := s.Throttle.wait(query.Context(), query.TableNames()); err != nil {
+				return nil, err
+			}
+		}
+
+		body, err := s.send(target, query)
+		if IsThrottle(err) {
+			query.state.throttles++
+		}
+		if s.Throttle != nil && (err == nil || IsThrottle(err)) {
+			s.Throttle.observe(query.TableNames(), err != nil)
+		}
+		if err == nil || query.Retries() >= s.MaxRetries || !IsRetryable(err) {
+			return body, err
+		}
+
+		delay := retryDelay(query.Retries())
+		s.log(LOG_INFO, "Retrying DynamoDB request", "target", target, "retry", query.Retries()+1, "delay", delay, "error", err)
+
+		if err := sleep(query.Context(), delay); err != nil {
+			return nil, err
+		}
+		query.state.retries++
+	}
+}
@@ -0,0 +1,99 @@
+package ddbomb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type RetrySuite struct{}
+
+var _ = gocheck.Suite(&RetrySuite{})
+
+// failingHandler answers the first failures requests with the given status
+// and error code, then with the canned response.
+func failingHandler(failures int, status int, code string, response string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"__type":"com.amazonaws.dynamodb.v20120810#%s","message":"try again"}`, code)
+			return
+		}
+		fmt.Fprint(w, response)
+	})
+}
+
+func (s *RetrySuite) TestThrottlesAreRetried(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(failingHandler(2, 400, "ProvisionedThroughputExceededException",
+		`{"Table": {"TableName": "sites", "TableStatus": "ACTIVE"}}`), &requests))
+	defer ts.Close()
+	server.MaxRetries = 2
+
+	var retries int
+	server.Use(func(next ddbomb.Handler) ddbomb.Handler {
+		return func(operation string, query *ddbomb.Query) ([]byte, error) {
+			response, err := next(operation, query)
+			retries = query.Retries()
+			return response, err
+		}
+	})
+
+	desc, err := server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(desc.TableStatus, gocheck.Equals, "ACTIVE")
+	c.Check(requests, gocheck.HasLen, 3)
+	c.Check(retries, gocheck.Equals, 2)
+}
+
+func (s *RetrySuite) TestRetriesRunOut(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(failingHandler(5, 500, "InternalServerError", `{}`), &requests))
+	defer ts.Close()
+	server.MaxRetries = 1
+
+	_, err := server.DescribeTable("sites")
+	c.Check(err, gocheck.ErrorMatches, "InternalServerError: try again")
+	c.Check(requests, gocheck.HasLen, 2)
+}
+
+func (s *RetrySuite) TestClientErrorsAreNotRetried(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(failingHandler(5, 400, "ValidationException", `{}`), &requests))
+	defer ts.Close()
+	server.MaxRetries = 3
+
+	_, err := server.DescribeTable("sites")
+	c.Check(err, gocheck.ErrorMatches, "ValidationException: try again")
+	c.Check(requests, gocheck.HasLen, 1)
+}
+
+func (s *RetrySuite) TestContextCancelsRequest(c *gocheck.C) {
+	ts, server := newTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer ts.Close()
+	server.MaxRetries = 3
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := server.WithContext(ctx).DescribeTable("sites")
+	c.Check(errors.Is(err, context.DeadlineExceeded), gocheck.Equals, true)
+}
+
+func (s *RetrySuite) TestParseConsumedCapacity(c *gocheck.C) {
+	c.Check(ddbomb.ParseConsumedCapacity([]byte(`{"ConsumedCapacity": {"TableName": "sites", "CapacityUnits": 0.5}}`)),
+		gocheck.DeepEquals, []ddbomb.ConsumedCapacityT{{TableName: "sites", CapacityUnits: 0.5}})
+	c.Check(ddbomb.ParseConsumedCapacity([]byte(`{"ConsumedCapacity": [{"TableName": "a", "CapacityUnits": 2}, {"TableName": "b", "CapacityUnits": 1}]}`)),
+		gocheck.DeepEquals, []ddbomb.ConsumedCapacityT{{TableName: "a", CapacityUnits: 2}, {TableName: "b", CapacityUnits: 1}})
+	c.Check(ddbomb.ParseConsumedCapacity([]byte(`{"Item": {}}`)), gocheck.IsNil)
+}
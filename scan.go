@@ -1,26 +1,30 @@
 package ddbomb
 
 import (
-	"errors"
-	"fmt"
 	simplejson "github.com/bitly/go-simplejson"
 )
 
 func (t *Table) FetchResults(query *Query) ([]map[string]Attribute, error) {
+	results, _, err := t.fetchPage(query)
+	return results, err
+}
+
+// fetchPage runs a Scan and also returns its LastEvaluatedKey, nil once the
+// scan is complete.
+func (t *Table) fetchPage(query *Query) ([]map[string]Attribute, map[string]interface{}, error) {
 	jsonResponse, err := t.Server.queryServer(target("Scan"), query)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	json, err := simplejson.NewJson(jsonResponse)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	itemCount, err := json.Get("Count").Int()
 	if err != nil {
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
+		return nil, nil, unexpectedResponse(jsonResponse)
 	}
 
 	results := make([]map[string]Attribute, itemCount)
@@ -28,13 +32,18 @@ func (t *Table) FetchResults(query *Query) ([]map[string]Attribute, error) {
 	for i, _ := range results {
 		item, err := json.Get("Items").GetIndex(i).Map()
 		if err != nil {
-			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-			return nil, errors.New(message)
+			return nil, nil, unexpectedResponse(jsonResponse)
 		}
-		results[i] = parseAttributes(item)
+		results[i] = t.Server.parseAttributes(item)
 	}
-	return results, nil
 
+	var lastKey map[string]interface{}
+	if lastKeyJson, ok := json.CheckGet("LastEvaluatedKey"); ok {
+		if lastKey, err = lastKeyJson.Map(); err != nil {
+			return nil, nil, unexpectedResponse(jsonResponse)
+		}
+	}
+	return results, lastKey, nil
 }
 
 func (t *Table) Scan(attributeComparisons ...AttributeComparison) ([]map[string]Attribute, error) {
@@ -49,3 +58,96 @@ func (t *Table) ParallelScan(attributeComparisons []AttributeComparison, segment
 	q.AddParallelScanConfiguration(segment, totalSegments)
 	return t.FetchResults(q)
 }
+
+// ScanOptions holds the optional parameters of a Scan request. Only the
+// fields that are set are sent to DynamoDB, and a nil *ScanOptions is the
+// same as the zero value.
+type ScanOptions struct {
+	Filter          []AttributeComparison
+	Limit           int64    // maximum number of items evaluated per page
+	ConsistentRead  bool     // not supported on global secondary indexes
+	IndexName       string   // scan a secondary index instead of the table
+	AttributesToGet []string // projection; all attributes when empty
+	Segment         int
+	TotalSegments   int // enables a parallel scan when greater than zero
+
+	// ExclusiveStartKey continues the scan from the LastEvaluatedKey
+	// returned by ScanPage for the previous page.
+	ExclusiveStartKey map[string]interface{}
+}
+
+// ScanWithOptions returns a single page of results; use ScanPage to get
+// the following ones.
+func (t *Table) ScanWithOptions(opts *ScanOptions) ([]map[string]Attribute, error) {
+	q := NewQuery(t)
+	q.AddScanOptions(opts)
+	return t.FetchResults(q)
+}
+
+// ScanPage returns a page of results along with the LastEvaluatedKey to set
+// as ExclusiveStartKey for the next page, which is nil after the last page:
+//
+//	opts := &ddbomb.ScanOptions{Limit: 100}
+//	for {
+//		items, lastKey, err := table.ScanPage(opts)
+//		...
+//		if lastKey == nil {
+//			break
+//		}
+//		opts.ExclusiveStartKey = lastKey
+//	}
+func (t *Table) ScanPage(opts *ScanOptions) ([]map[string]Attribute, map[string]interface{}, error) {
+	q := NewQuery(t)
+	q.AddScanOptions(opts)
+	return t.fetchPage(q)
+}
+
+// CountScan counts the items matching attributeComparisons using
+// Select: COUNT, following LastEvaluatedKey until the whole table is covered.
+func (t *Table) CountScan(attributeComparisons ...AttributeComparison) (int64, error) {
+	return t.CountScanWithOptions(&ScanOptions{Filter: attributeComparisons})
+}
+
+// CountScanWithOptions is like CountScan but accepts the full set of scan
+// options. Limit bounds the page size, not the total count.
+func (t *Table) CountScanWithOptions(opts *ScanOptions) (int64, error) {
+	// A projection cannot be combined with Select: COUNT.
+	var countOpts ScanOptions
+	if opts != nil {
+		countOpts = *opts
+	}
+	countOpts.AttributesToGet = nil
+
+	q := NewQuery(t)
+	q.AddScanOptions(&countOpts)
+	q.AddSelect("COUNT")
+
+	var total int64
+	for {
+		jsonResponse, err := t.Server.queryServer(target("Scan"), q)
+		if err != nil {
+			return 0, err
+		}
+
+		json, err := simplejson.NewJson(jsonResponse)
+		if err != nil {
+			return 0, err
+		}
+
+		itemCount, err := json.Get("Count").Int64()
+		if err != nil {
+			return 0, unexpectedResponse(jsonResponse)
+		}
+		total += itemCount
+
+		lastKeyJson, ok := json.CheckGet("LastEvaluatedKey")
+		if !ok {
+			return total, nil
+		}
+		lastKey, err := lastKeyJson.Map()
+		if err != nil {
+			return 0, unexpectedResponse(jsonResponse)
+		}
+		q.AddExclusiveStartKey(lastKey)
+	}
+}
@@ -0,0 +1,71 @@
+package ddbomb
+
+import "strings"
+
+// ServerOption configures the Server returned by NewServer.
+type ServerOption func(*serverConfig)
+
+type serverConfig struct {
+	credentials   CredentialsProvider
+	endpoint      EndpointOptions
+	url           string
+	signingRegion string
+}
+
+// WithCredentials signs the requests with the credentials of provider
+// instead of DefaultCredentials.
+func WithCredentials(provider CredentialsProvider) ServerOption {
+	return func(c *serverConfig) { c.credentials = provider }
+}
+
+// WithEndpoint sends the requests to url instead of the endpoint of the
+// region, signed for signingRegion. An empty signingRegion keeps the region
+// given to NewServer.
+func WithEndpoint(url, signingRegion string) ServerOption {
+	return func(c *serverConfig) { c.url, c.signingRegion = url, signingRegion }
+}
+
+// WithFIPS selects the FIPS endpoint of the region.
+func WithFIPS() ServerOption {
+	return func(c *serverConfig) { c.endpoint.FIPS = true }
+}
+
+// WithDualStack selects the dual-stack (IPv4 and IPv6) endpoint of the
+// region.
+func WithDualStack() ServerOption {
+	return func(c *serverConfig) { c.endpoint.DualStack = true }
+}
+
+// NewServer returns a Server for the region called region, such as
+// "us-east-1":
+//
+//	server, err := ddbomb.NewServer("eu-west-1", ddbomb.WithFIPS())
+func NewServer(region string, opts ...ServerOption) (*Server, error) {
+	var c serverConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if c.url != "" {
+		if c.signingRegion != "" {
+			region = c.signingRegion
+		}
+		return &Server{Credentials: c.credentials, Region: Region{Name: region, DynamoDBEndpoint: strings.TrimSuffix(c.url, "/")}}, nil
+	}
+
+	r, err := ResolveEndpoint(region, c.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{Credentials: c.credentials, Region: r}, nil
+}
+
+// NewLocalServer returns a Server for a local stand-in for DynamoDB at url,
+// such as DynamoDB Local or a ddbombtest.Fake. They accept any credentials,
+// so it signs with dummy ones.
+func NewLocalServer(url string) *Server {
+	return &Server{
+		Credentials: NewStaticCredentials("local", "local", ""),
+		Region:      Region{Name: "us-east-1", DynamoDBEndpoint: strings.TrimSuffix(url, "/")},
+	}
+}
@@ -0,0 +1,123 @@
+package ddbomb_test
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type SigV4Suite struct{}
+
+var _ = gocheck.Suite(&SigV4Suite{})
+
+// Cases of the AWS Signature Version 4 test suite, which all sign for
+// "service" in us-east-1 at 20150830T123600Z with the same credentials.
+var sigV4Vectors = []struct {
+	name, method, url string
+	headers           [][2]string
+	body              string
+	signedHeaders     string
+	signature         string
+}{
+	{"get-vanilla", "GET", "/", nil, "",
+		"host;x-amz-date", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+	{"get-vanilla-query-order-key-case", "GET", "/?Param2=value2&Param1=value1", nil, "",
+		"host;x-amz-date", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	{"get-vanilla-empty-query-key", "GET", "/?Param1=value1", nil, "",
+		"host;x-amz-date", "a67d582fa61cc504c4bae71f336f98b97f1ea3c7a6bfe1b6e45aec72011b9aeb"},
+	{"get-vanilla-query-unreserved", "GET",
+		"/?-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+		nil, "", "host;x-amz-date", "9c3e54bfcdf0b19771a7f523ee5669cdf59bc7cc0884027167c21bb143a40197"},
+	{"get-vanilla-utf8-query", "GET", "/?ሴ=bar", nil, "",
+		"host;x-amz-date", "2cdec8eed098649ff3a119c94853b13c643bcf08f8b0a1d91e12c9027818dd04"},
+	{"get-unreserved", "GET", "/-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", nil, "",
+		"host;x-amz-date", "07ef7494c76fa4850883e2b006601f940f8a34d404d0cfa977f52a65bbf5f24f"},
+	{"get-utf8", "GET", "/ሴ", nil, "",
+		"host;x-amz-date", "8318018e0b0f223aa2bbf98705b62bb787dc9c0e678f255a891fd03141be5d85"},
+	{"get-space", "GET", "/example%20space/", nil, "",
+		"host;x-amz-date", "652487583200325589f1fba4c7e578f72c47cb61beeca81406b39ddec1366741"},
+	{"get-relative", "GET", "/example/..", nil, "",
+		"host;x-amz-date", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+	{"get-slashes", "GET", "//example//", nil, "",
+		"host;x-amz-date", "9a624bd73a37c9a373b5312afbebe7a714a789de108f0bdfe846570885f57e84"},
+	{"get-slash-pointless-dot", "GET", "/./example", nil, "",
+		"host;x-amz-date", "ef75d96142cf21edca26f06005da7988e4f8dc83a165a80865db7089db637ec5"},
+	{"get-header-key-duplicate", "GET", "/", [][2]string{{"My-Header1", "value2"}, {"My-Header1", "value2"}, {"My-Header1", "value1"}}, "",
+		"host;my-header1;x-amz-date", "c9d5ea9f3f72853aea855b47ea873832890dbdd183b4468f858259531a5138ea"},
+	{"get-header-value-order", "GET", "/", [][2]string{{"My-Header1", "value4"}, {"My-Header1", "value1"}, {"My-Header1", "value3"}, {"My-Header1", "value2"}}, "",
+		"host;my-header1;x-amz-date", "08c7e5a9acfcfeb3ab6b2185e75ce8b1deb5e634ec47601a50643f830c755c01"},
+	{"get-header-value-trim", "GET", "/", [][2]string{{"My-Header1", " value1"}, {"My-Header2", ` "a   b   c"`}}, "",
+		"host;my-header1;my-header2;x-amz-date", "acc3ed3afb60bb290fc8d2dd0098b9911fcaa05412b367055dee359757a9c736"},
+	{"post-vanilla", "POST", "/", nil, "",
+		"host;x-amz-date", "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"},
+	{"post-vanilla-query", "POST", "/?Param1=value1", nil, "",
+		"host;x-amz-date", "28038455d6de14eafc1f9222cf5aa6f1a96197d7deb8263271d420d138af7f11"},
+	{"post-header-key-sort", "POST", "/", [][2]string{{"My-Header1", "value1"}}, "",
+		"host;my-header1;x-amz-date", "c5410059b04c1ee005303aed430f6e6645f61f4dc9e1461ec8f8916fdf18852c"},
+	{"post-header-value-case", "POST", "/", [][2]string{{"My-Header1", "VALUE1"}}, "",
+		"host;my-header1;x-amz-date", "cdbc9802e29d2942e5e10b5bccfdd67c5f22c7c4e8ae67b53629efa58b974b7d"},
+	{"post-x-www-form-urlencoded", "POST", "/", [][2]string{{"Content-Type", "application/x-www-form-urlencoded"}}, "Param1=value1",
+		"content-type;host;x-amz-date", "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a"},
+	{"post-x-www-form-urlencoded-parameters", "POST", "/", [][2]string{{"Content-Type", "application/x-www-form-urlencoded; charset=utf8"}}, "Param1=value1",
+		"content-type;host;x-amz-date", "1a72ec8f64bd914b0e42e42607c7fbce7fb2c7465f63e3092b3b0d39fa77a6fe"},
+}
+
+var sigV4Credentials = ddbomb.Credentials{AccessKeyId: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+func (s *SigV4Suite) TestTestSuite(c *gocheck.C) {
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	for _, v := range sigV4Vectors {
+		req, err := http.NewRequest(v.method, "https://example.amazonaws.com"+v.url, strings.NewReader(v.body))
+		c.Assert(err, gocheck.IsNil)
+		for _, h := range v.headers {
+			req.Header.Add(h[0], h[1])
+		}
+
+		ddbomb.SignV4(req, []byte(v.body), sigV4Credentials, "us-east-1", "service", now)
+		c.Check(req.Header.Get("X-Amz-Date"), gocheck.Equals, "20150830T123600Z")
+		c.Check(req.Header.Get("Authorization"), gocheck.Equals,
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders="+
+				v.signedHeaders+", Signature="+v.signature, gocheck.Commentf(v.name))
+	}
+}
+
+// The example of the AWS General Reference, which signs a call to IAM.
+func (s *SigV4Suite) TestDocumentationExample(c *gocheck.C) {
+	req, err := http.NewRequest("GET", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	c.Assert(err, gocheck.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	ddbomb.SignV4(req, nil, sigV4Credentials, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	c.Check(req.Header.Get("Authorization"), gocheck.Equals,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, "+
+			"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7")
+}
+
+func (s *SigV4Suite) TestSessionTokenIsSigned(c *gocheck.C) {
+	req, err := http.NewRequest("POST", "https://dynamodb.us-east-1.amazonaws.com/", nil)
+	c.Assert(err, gocheck.IsNil)
+	creds := sigV4Credentials
+	creds.SessionToken = "TOKEN"
+
+	ddbomb.SignV4(req, nil, creds, "us-east-1", "dynamodb", time.Now())
+	c.Check(req.Header.Get("X-Amz-Security-Token"), gocheck.Equals, "TOKEN")
+	c.Check(req.Header.Get("Authorization"), gocheck.Matches, `.*SignedHeaders=host;x-amz-date;x-amz-security-token, .*`)
+}
+
+func (s *SigV4Suite) TestServerSignsRequests(c *gocheck.C) {
+	var authorization string
+	ts, server := newTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"TableNames": []}`))
+	}))
+	defer ts.Close()
+	server.Region.Name = "eu-west-1"
+
+	_, err := server.ListTables()
+	c.Assert(err, gocheck.IsNil)
+	c.Check(authorization, gocheck.Matches,
+		`AWS4-HMAC-SHA256 Credential=local/\d{8}/eu-west-1/dynamodb/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-target, Signature=[0-9a-f]{64}`)
+}
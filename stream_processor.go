@@ -0,0 +1,438 @@
+package ddbomb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Attributes stored on each item of a lease table, keyed by shard id.
+const (
+	leaseKeyAttr      = "ShardId"
+	leaseOwnerAttr    = "LeaseOwner"
+	leaseExpiresAttr  = "LeaseExpires" // unix nanoseconds
+	checkpointAttr    = "Checkpoint"   // last sequence number handled
+	shardFinishedAttr = "ShardFinished"
+)
+
+var errLeaseLost = errors.New("Shard lease taken by another worker")
+
+// leaseReleaseTimeout bounds the release of the leases on shutdown, which
+// goes on after the context of Run is done.
+const leaseReleaseTimeout = 5 * time.Second
+
+// StreamHandler is called with every non-empty batch of records read from
+// a shard. Records are delivered at least once: a batch is checkpointed
+// only after the handler returns nil, and an error stops the processor.
+type StreamHandler func(shardId string, records []StreamRecordT) error
+
+// StreamProcessor reads every shard of a stream, children only after their
+// parents are finished, and checkpoints progress to a lease table so that
+// several workers can share the shards between them.
+type StreamProcessor struct {
+	Streams   *StreamsServer
+	StreamArn string
+	Leases    TableAPI // see LeaseTableDescription
+	WorkerId  string   // must be unique among the workers sharing Leases
+	Handler   StreamHandler
+
+	BatchSize         int64         // records per GetRecords; service maximum when zero
+	PollInterval      time.Duration // wait after a GetRecords returns nothing
+	ShardSyncInterval time.Duration // how often shards and leases are rediscovered
+	LeaseDuration     time.Duration // how long a lease is held without renewal
+
+	mu       sync.Mutex
+	active   map[string]bool
+	finished map[string]bool
+}
+
+type shardLease struct {
+	exists      bool
+	owner       string
+	expires     string
+	checkpoint  string
+	finished    bool
+	lastRenewed time.Time
+}
+
+func NewStreamProcessor(streams *StreamsServer, streamArn string, leases TableAPI, workerId string, handler StreamHandler) *StreamProcessor {
+	return &StreamProcessor{
+		Streams:           streams,
+		StreamArn:         streamArn,
+		Leases:            leases,
+		WorkerId:          workerId,
+		Handler:           handler,
+		PollInterval:      time.Second,
+		ShardSyncInterval: 30 * time.Second,
+		LeaseDuration:     time.Minute,
+	}
+}
+
+// LeaseTableDescription describes an on-demand table that can hold the
+// leases of a StreamProcessor.
+func LeaseTableDescription(name string) TableDescriptionT {
+	return TableDescriptionT{
+		TableName:   name,
+		BillingMode: BILLING_PAY_PER_REQUEST,
+		AttributeDefinitions: []AttributeDefinitionT{
+			AttributeDefinitionT{leaseKeyAttr, STRING},
+		},
+		KeySchema: []KeySchemaT{
+			KeySchemaT{leaseKeyAttr, HASH_KEY},
+		},
+	}
+}
+
+// NewLeaseTable returns the table for a lease table created from
+// LeaseTableDescription.
+func (s *Server) NewLeaseTable(name string) TableAPI {
+	return s.OpenTable(name, PrimaryKey{KeyAttribute: NewStringAttribute(leaseKeyAttr, "")})
+}
+
+// Run processes the stream until ctx is done or the handler, the stream or
+// the lease table returns an error. Requests in flight are abandoned with
+// ctx, and the leases of the shards being read are given up on return, so
+// that other workers can take them over without waiting for them to expire.
+func (p *StreamProcessor) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	errs := make(chan error, 1)
+
+	stop := func(err error) error {
+		cancel()
+		wg.Wait()
+		return err
+	}
+
+	p.mu.Lock()
+	p.active = map[string]bool{}
+	p.finished = map[string]bool{}
+	p.mu.Unlock()
+
+	for {
+		if err := p.syncShards(ctx, &wg, errs); err != nil {
+			if ctx.Err() != nil {
+				// err is that of a request abandoned because of ctx.
+				return stop(ctx.Err())
+			}
+			return stop(err)
+		}
+
+		timer := time.NewTimer(p.ShardSyncInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return stop(ctx.Err())
+		case err := <-errs:
+			timer.Stop()
+			return stop(err)
+		case <-timer.C:
+		}
+	}
+}
+
+// leases returns the lease table with its operations running under ctx,
+// when it is a *Table.
+func (p *StreamProcessor) leases(ctx context.Context) TableAPI {
+	if t, ok := p.Leases.(*Table); ok {
+		return t.WithContext(ctx)
+	}
+	return p.Leases
+}
+
+// syncShards starts a reader for every shard that is ready, not already
+// being read here and not leased to a live worker elsewhere.
+func (p *StreamProcessor) syncShards(ctx context.Context, wg *sync.WaitGroup, errs chan<- error) error {
+	desc, err := p.Streams.WithContext(ctx).DescribeStream(p.StreamArn)
+	if err != nil {
+		return err
+	}
+
+	inStream := map[string]bool{}
+	for _, shard := range desc.Shards {
+		inStream[shard.ShardId] = true
+	}
+
+	for _, shard := range desc.Shards {
+		if p.isActive(shard.ShardId) || p.isFinished(shard.ShardId) {
+			continue
+		}
+
+		// Parents that have been trimmed from the stream can't hold anything back.
+		if shard.ParentShardId != "" && inStream[shard.ParentShardId] {
+			done, err := p.shardFinished(ctx, shard.ParentShardId)
+			if err != nil {
+				return err
+			}
+			if !done {
+				continue
+			}
+		}
+
+		lease, err := p.acquireLease(ctx, shard.ShardId)
+		if err != nil {
+			return err
+		}
+		if lease == nil {
+			continue
+		}
+		if lease.finished {
+			p.setFinished(shard.ShardId)
+			continue
+		}
+
+		shardId := shard.ShardId
+		p.setActive(shardId, true)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer p.setActive(shardId, false)
+
+			err := p.processShard(ctx, shardId, lease)
+			if err != nil && err != errLeaseLost {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// processShard reads a shard until it is finished, ctx is done or an error
+// occurs. Unless the shard is finished or its lease was lost, the lease is
+// then released so that another worker can take the shard over right away.
+func (p *StreamProcessor) processShard(ctx context.Context, shardId string, lease *shardLease) error {
+	finished, err := p.readShard(ctx, shardId, lease)
+	if finished || err == errLeaseLost {
+		return err
+	}
+
+	releaseErr := p.releaseLease(ctx, shardId, lease)
+	if err == nil && releaseErr != errLeaseLost {
+		err = releaseErr
+	}
+	return err
+}
+
+// readShard reports whether it read the shard to its end, rather than
+// stopping because ctx is done.
+func (p *StreamProcessor) readShard(ctx context.Context, shardId string, lease *shardLease) (bool, error) {
+	streams := p.Streams.WithContext(ctx)
+
+	var iteratorType ShardIteratorType = ITERATOR_TRIM_HORIZON
+	if lease.checkpoint != "" {
+		iteratorType = ITERATOR_AFTER_SEQUENCE_NUMBER
+	}
+
+	iterator, err := streams.GetShardIterator(p.StreamArn, shardId, iteratorType, lease.checkpoint)
+	if ctx.Err() != nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for iterator != "" {
+		records, next, err := streams.GetRecords(iterator, p.BatchSize)
+		if ctx.Err() != nil {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		iterator = next
+
+		if len(records) > 0 {
+			if err := p.Handler(shardId, records); err != nil {
+				return false, err
+			}
+			lease.checkpoint = records[len(records)-1].Dynamodb.SequenceNumber
+			if err := p.renewLease(ctx, shardId, lease, false); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if time.Since(lease.lastRenewed) > p.LeaseDuration/3 {
+			if err := p.renewLease(ctx, shardId, lease, false); err != nil {
+				return false, err
+			}
+		}
+
+		if iterator != "" {
+			if sleep(ctx, p.PollInterval) != nil {
+				return false, nil
+			}
+		}
+	}
+
+	// The shard is closed and has been read to its end.
+	if err := p.renewLease(ctx, shardId, lease, true); err != nil {
+		return false, err
+	}
+	p.setFinished(shardId)
+	return true, nil
+}
+
+// shardFinished reports whether a shard has been read to its end by this
+// or any other worker.
+func (p *StreamProcessor) shardFinished(ctx context.Context, shardId string) (bool, error) {
+	if p.isFinished(shardId) {
+		return true, nil
+	}
+	lease, err := p.readLease(ctx, shardId)
+	if err != nil {
+		return false, err
+	}
+	if lease.finished {
+		p.setFinished(shardId)
+	}
+	return lease.finished, nil
+}
+
+func (p *StreamProcessor) readLease(ctx context.Context, shardId string) (*shardLease, error) {
+	item, err := p.leases(ctx).GetItemConsistent(&Key{HashKey: shardId}, true)
+	if err == ErrNotFound {
+		return &shardLease{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &shardLease{
+		exists:     true,
+		owner:      item[leaseOwnerAttr].Value,
+		expires:    item[leaseExpiresAttr].Value,
+		checkpoint: item[checkpointAttr].Value,
+		finished:   item[shardFinishedAttr].Value == "1",
+	}, nil
+}
+
+// acquireLease takes the lease of a shard if it is free, expired or already
+// ours. It returns nil when another worker holds or wins the lease.
+func (p *StreamProcessor) acquireLease(ctx context.Context, shardId string) (*shardLease, error) {
+	lease, err := p.readLease(ctx, shardId)
+	if err != nil || lease.finished {
+		return lease, err
+	}
+
+	now := time.Now()
+	if lease.exists && lease.owner != p.WorkerId {
+		expires, _ := strconv.ParseInt(lease.expires, 10, 64)
+		if expires > now.UnixNano() {
+			return nil, nil
+		}
+	}
+
+	newExpires := strconv.FormatInt(now.Add(p.LeaseDuration).UnixNano(), 10)
+	attrs := []Attribute{
+		*NewStringAttribute(leaseOwnerAttr, p.WorkerId),
+		*NewNumericAttribute(leaseExpiresAttr, newExpires),
+	}
+
+	if lease.exists {
+		// Only take over the lease exactly as we read it.
+		expected := []Attribute{
+			*NewStringAttribute(leaseOwnerAttr, lease.owner),
+			*NewNumericAttribute(leaseExpiresAttr, lease.expires),
+		}
+		_, err = p.leases(ctx).ConditionalUpdateAttributes(&Key{HashKey: shardId}, attrs, expected)
+	} else {
+		expected := []Attribute{
+			*NewStringAttribute(leaseKeyAttr, "").SetExists(false),
+		}
+		_, err = p.leases(ctx).ConditionalPutItem(shardId, "", attrs, expected)
+	}
+	if errors.Is(err, ErrConditionalCheckFailed) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lease.exists = true
+	lease.owner = p.WorkerId
+	lease.expires = newExpires
+	lease.lastRenewed = now
+	return lease, nil
+}
+
+// renewLease extends a lease we hold and records its checkpoint, marking
+// the shard finished if asked to. It returns errLeaseLost when another
+// worker has taken the lease over.
+func (p *StreamProcessor) renewLease(ctx context.Context, shardId string, lease *shardLease, finished bool) error {
+	now := time.Now()
+	if err := p.writeLease(ctx, shardId, lease, now.Add(p.LeaseDuration), finished); err != nil {
+		return err
+	}
+	lease.lastRenewed = now
+	return nil
+}
+
+// releaseLease expires a lease we hold, keeping its checkpoint. It runs
+// even if ctx is done, as it is how a worker shuts down.
+func (p *StreamProcessor) releaseLease(ctx context.Context, shardId string, lease *shardLease) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaseReleaseTimeout)
+	defer cancel()
+	return p.writeLease(ctx, shardId, lease, time.Unix(0, 0), false)
+}
+
+func (p *StreamProcessor) writeLease(ctx context.Context, shardId string, lease *shardLease, expires time.Time, finished bool) error {
+	newExpires := strconv.FormatInt(expires.UnixNano(), 10)
+
+	attrs := []Attribute{
+		*NewNumericAttribute(leaseExpiresAttr, newExpires),
+	}
+	if lease.checkpoint != "" {
+		attrs = append(attrs, *NewStringAttribute(checkpointAttr, lease.checkpoint))
+	}
+	if finished {
+		attrs = append(attrs, *NewNumericAttribute(shardFinishedAttr, "1"))
+	}
+	expected := []Attribute{
+		*NewStringAttribute(leaseOwnerAttr, p.WorkerId),
+	}
+
+	_, err := p.leases(ctx).ConditionalUpdateAttributes(&Key{HashKey: shardId}, attrs, expected)
+	if errors.Is(err, ErrConditionalCheckFailed) {
+		return errLeaseLost
+	}
+	if err != nil {
+		return err
+	}
+
+	lease.expires = newExpires
+	return nil
+}
+
+func (p *StreamProcessor) isActive(shardId string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active[shardId]
+}
+
+func (p *StreamProcessor) setActive(shardId string, active bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if active {
+		p.active[shardId] = true
+	} else {
+		delete(p.active, shardId)
+	}
+}
+
+func (p *StreamProcessor) isFinished(shardId string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.finished[shardId]
+}
+
+func (p *StreamProcessor) setFinished(shardId string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finished[shardId] = true
+}
@@ -0,0 +1,156 @@
+package ddbomb_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type StreamProcessorSuite struct{}
+
+var _ = gocheck.Suite(&StreamProcessorSuite{})
+
+// streamHandler serves a closed parent shard and its open child, each with
+// a single record, plus a lease table on which every write succeeds.
+func streamHandler(mu *sync.Mutex, leaseWrites *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.Header.Get("X-Amz-Target") {
+		case "DynamoDBStreams_20120810.DescribeStream":
+			fmt.Fprint(w, `{"StreamDescription": {"StreamArn": "arn:s", "Shards": [
+			  {"ShardId": "child", "ParentShardId": "parent"},
+			  {"ShardId": "parent", "SequenceNumberRange": {"StartingSequenceNumber": "1", "EndingSequenceNumber": "1"}}]}}`)
+		case "DynamoDBStreams_20120810.GetShardIterator":
+			if strings.Contains(string(body), `"ShardId":"parent"`) {
+				fmt.Fprint(w, `{"ShardIterator": "parent-0"}`)
+			} else {
+				fmt.Fprint(w, `{"ShardIterator": "child-0"}`)
+			}
+		case "DynamoDBStreams_20120810.GetRecords":
+			switch {
+			case strings.Contains(string(body), "parent-0"):
+				fmt.Fprint(w, `{"Records": [{"eventName": "INSERT", "dynamodb": {"SequenceNumber": "1"}}]}`)
+			case strings.Contains(string(body), "child-0"):
+				fmt.Fprint(w, `{"Records": [{"eventName": "MODIFY", "dynamodb": {"SequenceNumber": "2"}}], "NextShardIterator": "child-1"}`)
+			default:
+				fmt.Fprint(w, `{"Records": [], "NextShardIterator": "child-1"}`)
+			}
+		case "DynamoDB_20120810.GetItem":
+			fmt.Fprint(w, `{}`)
+		default:
+			mu.Lock()
+			*leaseWrites = append(*leaseWrites, r.Header.Get("X-Amz-Target")+" "+string(body))
+			mu.Unlock()
+			fmt.Fprint(w, `{}`)
+		}
+	})
+}
+
+func (s *StreamProcessorSuite) TestParentShardIsProcessedFirst(c *gocheck.C) {
+	var mu sync.Mutex
+	var leaseWrites []string
+	ts, server := newTestServer(streamHandler(&mu, &leaseWrites))
+	defer ts.Close()
+
+	var handled []string
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	streams := ddbomb.NewStreamsServer(server)
+	p := ddbomb.NewStreamProcessor(streams, "arn:s", server.NewLeaseTable("leases"), "worker-1",
+		func(shardId string, records []ddbomb.StreamRecordT) error {
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, shardId+":"+records[0].Dynamodb.SequenceNumber)
+			if len(handled) == 2 {
+				cancel()
+			}
+			return nil
+		})
+	p.PollInterval = 10 * time.Millisecond
+	p.ShardSyncInterval = 10 * time.Millisecond
+
+	c.Check(p.Run(ctx), gocheck.Equals, context.Canceled)
+	c.Check(handled, gocheck.DeepEquals, []string{"parent:1", "child:2"})
+
+	var parentDone, childCheckpoint bool
+	for _, write := range leaseWrites {
+		if strings.HasPrefix(write, "DynamoDB_20120810.UpdateItem") && strings.Contains(write, `"ShardId":{"S":"parent"}`) &&
+			strings.Contains(write, `"ShardFinished":{"Action":"PUT","Value":{"N":"1"}}`) {
+			parentDone = true
+		}
+		if strings.HasPrefix(write, "DynamoDB_20120810.UpdateItem") && strings.Contains(write, `"ShardId":{"S":"child"}`) &&
+			strings.Contains(write, `"Checkpoint":{"Action":"PUT","Value":{"S":"2"}}`) {
+			childCheckpoint = true
+		}
+	}
+	c.Check(parentDone, gocheck.Equals, true)
+	c.Check(childCheckpoint, gocheck.Equals, true)
+
+	// The child lease is given up on shutdown, checkpoint included.
+	last := leaseWrites[len(leaseWrites)-1]
+	c.Check(strings.Contains(last, `"ShardId":{"S":"child"}`), gocheck.Equals, true)
+	c.Check(strings.Contains(last, `"LeaseExpires":{"Action":"PUT","Value":{"N":"0"}}`), gocheck.Equals, true)
+	c.Check(strings.Contains(last, `"Checkpoint":{"Action":"PUT","Value":{"S":"2"}}`), gocheck.Equals, true)
+}
+
+func (s *StreamProcessorSuite) TestShutdownAbandonsRequests(c *gocheck.C) {
+	var mu sync.Mutex
+	var leaseWrites []string
+	records := streamHandler(&mu, &leaseWrites)
+	ts, server := newTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") == "DynamoDBStreams_20120810.GetRecords" {
+			// Hangs until the request is abandoned, which the server only
+			// notices once the body is read.
+			ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+			return
+		}
+		records.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	streams := ddbomb.NewStreamsServer(server)
+	p := ddbomb.NewStreamProcessor(streams, "arn:s", server.NewLeaseTable("leases"), "worker-1",
+		func(shardId string, records []ddbomb.StreamRecordT) error {
+			return nil
+		})
+
+	start := time.Now()
+	c.Check(p.Run(ctx), gocheck.Equals, context.DeadlineExceeded)
+	c.Check(time.Since(start) < 5*time.Second, gocheck.Equals, true)
+
+	mu.Lock()
+	defer mu.Unlock()
+	c.Assert(leaseWrites, gocheck.Not(gocheck.HasLen), 0)
+	last := leaseWrites[len(leaseWrites)-1]
+	c.Check(strings.Contains(last, `"ShardId":{"S":"parent"}`), gocheck.Equals, true)
+	c.Check(strings.Contains(last, `"LeaseExpires":{"Action":"PUT","Value":{"N":"0"}}`), gocheck.Equals, true)
+}
+
+func (s *StreamProcessorSuite) TestHandlerErrorStopsProcessor(c *gocheck.C) {
+	var mu sync.Mutex
+	var leaseWrites []string
+	ts, server := newTestServer(streamHandler(&mu, &leaseWrites))
+	defer ts.Close()
+
+	streams := ddbomb.NewStreamsServer(server)
+	failure := fmt.Errorf("handler failed")
+	p := ddbomb.NewStreamProcessor(streams, "arn:s", server.NewLeaseTable("leases"), "worker-1",
+		func(shardId string, records []ddbomb.StreamRecordT) error {
+			return failure
+		})
+	p.ShardSyncInterval = 10 * time.Millisecond
+
+	c.Check(p.Run(context.Background()), gocheck.Equals, failure)
+}
@@ -0,0 +1,225 @@
+package ddbomb
+
+// StreamsServer talks to DynamoDB Streams. It shares Server's signing and
+// transport, but sends requests to the streams endpoint of the region.
+type StreamsServer struct {
+	Server *Server
+}
+
+type StreamT struct {
+	StreamArn   string
+	StreamLabel string
+	TableName   string
+}
+
+type SequenceNumberRangeT struct {
+	EndingSequenceNumber   string // empty while the shard is still open
+	StartingSequenceNumber string
+}
+
+type ShardT struct {
+	ParentShardId       string
+	SequenceNumberRange SequenceNumberRangeT
+	ShardId             string
+}
+
+type StreamDescriptionT struct {
+	CreationRequestDateTime EpochTime
+	KeySchema               []KeySchemaT
+	Shards                  []ShardT
+	StreamArn               string
+	StreamLabel             string
+	StreamStatus            string // ENABLING, ENABLED, DISABLING or DISABLED
+	StreamViewType          StreamViewType
+	TableName               string
+}
+
+type StreamRecordDataT struct {
+	ApproximateCreationDateTime EpochTime
+	Keys                        map[string]Attribute
+	NewImage                    map[string]Attribute
+	OldImage                    map[string]Attribute
+	SequenceNumber              string
+	SizeBytes                   int64
+	StreamViewType              StreamViewType
+}
+
+type StreamRecordT struct {
+	AwsRegion    string
+	Dynamodb     StreamRecordDataT
+	EventID      string
+	EventName    string // INSERT, MODIFY or REMOVE
+	EventSource  string
+	EventVersion string
+}
+
+// streamRecordData mirrors StreamRecordDataT as it appears on the wire, so
+// the images can be run through parseAttributes.
+type streamRecordData struct {
+	ApproximateCreationDateTime EpochTime
+	Keys                        map[string]interface{}
+	NewImage                    map[string]interface{}
+	OldImage                    map[string]interface{}
+	SequenceNumber              string
+	SizeBytes                   int64
+	StreamViewType              StreamViewType
+}
+
+type streamRecord struct {
+	AwsRegion    string
+	Dynamodb     streamRecordData
+	EventID      string
+	EventName    string
+	EventSource  string
+	EventVersion string
+}
+
+type listStreamsResponse struct {
+	LastEvaluatedStreamArn string
+	Streams                []StreamT
+}
+
+type describeStreamResponse struct {
+	StreamDescription struct {
+		StreamDescriptionT
+		LastEvaluatedShardId string
+	}
+}
+
+type getShardIteratorResponse struct {
+	ShardIterator string
+}
+
+type getRecordsResponse struct {
+	NextShardIterator string
+	Records           []streamRecord
+}
+
+// NewStreamsServer returns a client for the streams endpoint matching the
+// endpoint of server, FIPS and dual-stack variants included. It works on a
+// copy of server, so it keeps its credentials, transport, middleware,
+// logger, retries and the rest of its settings. Endpoints the resolver
+// doesn't know, such as DynamoDB Local, serve streams from the same URL
+// and are used unchanged.
+func NewStreamsServer(server *Server) *StreamsServer {
+	s := *server
+	s.Region = server.Region.streamsRegion()
+	// Middleware added to one of the Servers later isn't added to the other.
+	s.Middleware = s.Middleware[:len(s.Middleware):len(s.Middleware)]
+	return &StreamsServer{&s}
+}
+
+// ListStreams returns every stream of tableName, or of all tables when
+// tableName is empty, following LastEvaluatedStreamArn across pages.
+func (s *StreamsServer) ListStreams(tableName string) ([]StreamT, error) {
+	var streams []StreamT
+
+	q := NewEmptyQuery()
+	if tableName != "" {
+		q.addTableByName(tableName)
+	}
+
+	for {
+		var r listStreamsResponse
+		if err := s.Server.queryServerInto(streamsTarget("ListStreams"), q, &r); err != nil {
+			return nil, err
+		}
+		streams = append(streams, r.Streams...)
+
+		if r.LastEvaluatedStreamArn == "" {
+			return streams, nil
+		}
+		q.AddExclusiveStartStreamArn(r.LastEvaluatedStreamArn)
+	}
+}
+
+// DescribeStream returns the stream description with the shards of every
+// page merged into Shards.
+func (s *StreamsServer) DescribeStream(streamArn string) (*StreamDescriptionT, error) {
+	var desc *StreamDescriptionT
+
+	q := NewEmptyQuery()
+	q.AddStreamArn(streamArn)
+
+	for {
+		var r describeStreamResponse
+		if err := s.Server.queryServerInto(streamsTarget("DescribeStream"), q, &r); err != nil {
+			return nil, err
+		}
+		if desc == nil {
+			desc = &r.StreamDescription.StreamDescriptionT
+		} else {
+			desc.Shards = append(desc.Shards, r.StreamDescription.Shards...)
+		}
+
+		if r.StreamDescription.LastEvaluatedShardId == "" {
+			return desc, nil
+		}
+		q.AddExclusiveStartShardId(r.StreamDescription.LastEvaluatedShardId)
+	}
+}
+
+// GetShardIterator returns an iterator positioned in the shard according to
+// iteratorType. sequenceNumber is only used by the AT_ and
+// AFTER_SEQUENCE_NUMBER types.
+func (s *StreamsServer) GetShardIterator(streamArn, shardId string, iteratorType ShardIteratorType, sequenceNumber string) (string, error) {
+	q := NewEmptyQuery()
+	q.AddStreamArn(streamArn)
+	q.AddShardIteratorPosition(shardId, iteratorType, sequenceNumber)
+
+	var r getShardIteratorResponse
+	if err := s.Server.queryServerInto(streamsTarget("GetShardIterator"), q, &r); err != nil {
+		return "", err
+	}
+	return r.ShardIterator, nil
+}
+
+// GetRecords reads up to limit records (the service maximum when limit is
+// zero) and returns them with the iterator for the next call. The next
+// iterator is empty once a closed shard has been read to its end.
+func (s *StreamsServer) GetRecords(shardIterator string, limit int64) ([]StreamRecordT, string, error) {
+	q := NewEmptyQuery()
+	q.AddShardIterator(shardIterator)
+	if limit > 0 {
+		q.AddLimit(limit)
+	}
+
+	var r getRecordsResponse
+	if err := s.Server.queryServerInto(streamsTarget("GetRecords"), q, &r); err != nil {
+		return nil, "", err
+	}
+
+	records := make([]StreamRecordT, len(r.Records))
+	for i, rec := range r.Records {
+		records[i] = StreamRecordT{
+			AwsRegion: rec.AwsRegion,
+			Dynamodb: StreamRecordDataT{
+				ApproximateCreationDateTime: rec.Dynamodb.ApproximateCreationDateTime,
+				Keys:                        s.parseImage(rec.Dynamodb.Keys),
+				NewImage:                    s.parseImage(rec.Dynamodb.NewImage),
+				OldImage:                    s.parseImage(rec.Dynamodb.OldImage),
+				SequenceNumber:              rec.Dynamodb.SequenceNumber,
+				SizeBytes:                   rec.Dynamodb.SizeBytes,
+				StreamViewType:              rec.Dynamodb.StreamViewType,
+			},
+			EventID:      rec.EventID,
+			EventName:    rec.EventName,
+			EventSource:  rec.EventSource,
+			EventVersion: rec.EventVersion,
+		}
+	}
+	return records, r.NextShardIterator, nil
+}
+
+// parseImage is parseAttributes, except that an image missing from the
+// record (such as OldImage on an INSERT) stays nil.
+func (s *StreamsServer) parseImage(image map[string]interface{}) map[string]Attribute {
+	if image == nil {
+		return nil
+	}
+	return s.Server.parseAttributes(image)
+}
+
+func streamsTarget(name string) string {
+	return "DynamoDBStreams_20120810." + name
+}
@@ -0,0 +1,111 @@
+package ddbomb_test
+
+import (
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type StreamsSuite struct{}
+
+var _ = gocheck.Suite(&StreamsSuite{})
+
+func (s *StreamsSuite) TestStreamsEndpoint(c *gocheck.C) {
+	for _, t := range []struct {
+		region   string
+		opts     []ddbomb.ServerOption
+		endpoint string
+	}{
+		{"us-east-1", nil, "https://streams.dynamodb.us-east-1.amazonaws.com"},
+		{"us-east-1", []ddbomb.ServerOption{ddbomb.WithFIPS()}, "https://streams.dynamodb-fips.us-east-1.amazonaws.com"},
+		{"eu-west-1", []ddbomb.ServerOption{ddbomb.WithDualStack()}, "https://streams.dynamodb.eu-west-1.api.aws"},
+		{"cn-north-1", nil, "https://streams.dynamodb.cn-north-1.amazonaws.com.cn"},
+		{"us-east-1", []ddbomb.ServerOption{ddbomb.WithEndpoint("http://127.0.0.1:8000", "")}, "http://127.0.0.1:8000"},
+	} {
+		server, err := ddbomb.NewServer(t.region, t.opts...)
+		c.Assert(err, gocheck.IsNil)
+		streams := ddbomb.NewStreamsServer(server)
+		c.Check(streams.Server.Region.DynamoDBEndpoint, gocheck.Equals, t.endpoint)
+		c.Check(streams.Server.Region.Name, gocheck.Equals, t.region)
+	}
+}
+
+func (s *StreamsSuite) TestStreamsServerKeepsSettings(c *gocheck.C) {
+	server := ddbomb.NewLocalServer("http://127.0.0.1:8000")
+	server.MaxRetries = 3
+	server.DisableCRC32Check = true
+	server.Breaker = &ddbomb.CircuitBreaker{}
+	server.Use(func(next ddbomb.Handler) ddbomb.Handler { return next })
+
+	streams := ddbomb.NewStreamsServer(server)
+	c.Check(streams.Server.Credentials, gocheck.Equals, server.Credentials)
+	c.Check(streams.Server.MaxRetries, gocheck.Equals, 3)
+	c.Check(streams.Server.DisableCRC32Check, gocheck.Equals, true)
+	c.Check(streams.Server.Breaker, gocheck.Equals, server.Breaker)
+	c.Check(streams.Server.Middleware, gocheck.HasLen, 1)
+
+	streams.Server.Use(func(next ddbomb.Handler) ddbomb.Handler { return next })
+	c.Check(server.Middleware, gocheck.HasLen, 1)
+}
+
+func (s *StreamsSuite) TestDescribeStreamMergesShardPages(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(cannedHandler(
+		`{"StreamDescription": {"StreamArn": "arn:s", "StreamStatus": "ENABLED", "StreamViewType": "NEW_AND_OLD_IMAGES",
+		  "Shards": [{"ShardId": "shard-1", "SequenceNumberRange": {"StartingSequenceNumber": "100", "EndingSequenceNumber": "200"}}],
+		  "LastEvaluatedShardId": "shard-1"}}`,
+		`{"StreamDescription": {"StreamArn": "arn:s", "Shards": [{"ShardId": "shard-2", "ParentShardId": "shard-1"}]}}`,
+	), &requests))
+	defer ts.Close()
+
+	streams := ddbomb.NewStreamsServer(server)
+	desc, err := streams.DescribeStream("arn:s")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(desc.StreamViewType, gocheck.Equals, ddbomb.STREAM_NEW_AND_OLD_IMAGES)
+	c.Assert(desc.Shards, gocheck.HasLen, 2)
+	c.Check(desc.Shards[0].SequenceNumberRange.EndingSequenceNumber, gocheck.Equals, "200")
+	c.Check(desc.Shards[1].ParentShardId, gocheck.Equals, "shard-1")
+	c.Check(requests[1], gocheck.Equals, `DynamoDBStreams_20120810.DescribeStream {"ExclusiveStartShardId":"shard-1","StreamArn":"arn:s"}`)
+}
+
+func (s *StreamsSuite) TestGetRecords(c *gocheck.C) {
+	ts, server := cannedServer(`
+{
+  "NextShardIterator": "iter-2",
+  "Records": [{
+    "eventID": "e1",
+    "eventName": "MODIFY",
+    "eventSource": "aws:dynamodb",
+    "dynamodb": {
+      "ApproximateCreationDateTime": 1540000000,
+      "Keys": {"domain": {"S": "example.com"}},
+      "NewImage": {"domain": {"S": "example.com"}, "hits": {"N": "2"}},
+      "OldImage": {"domain": {"S": "example.com"}, "hits": {"N": "1"}},
+      "SequenceNumber": "101",
+      "SizeBytes": 42,
+      "StreamViewType": "NEW_AND_OLD_IMAGES"
+    }
+  }, {
+    "eventID": "e2",
+    "eventName": "INSERT",
+    "dynamodb": {
+      "Keys": {"domain": {"S": "example.org"}},
+      "NewImage": {"domain": {"S": "example.org"}},
+      "SequenceNumber": "102"
+    }
+  }]
+}`)
+	defer ts.Close()
+
+	streams := ddbomb.NewStreamsServer(server)
+	records, next, err := streams.GetRecords("iter-1", 100)
+	c.Assert(err, gocheck.IsNil)
+	c.Check(next, gocheck.Equals, "iter-2")
+	c.Assert(records, gocheck.HasLen, 2)
+
+	c.Check(records[0].EventName, gocheck.Equals, "MODIFY")
+	c.Check(records[0].Dynamodb.NewImage["hits"], gocheck.DeepEquals, *ddbomb.NewNumericAttribute("hits", "2"))
+	c.Check(records[0].Dynamodb.OldImage["hits"], gocheck.DeepEquals, *ddbomb.NewNumericAttribute("hits", "1"))
+	c.Check(records[0].Dynamodb.Keys["domain"], gocheck.DeepEquals, *ddbomb.NewStringAttribute("domain", "example.com"))
+	c.Check(records[0].Dynamodb.ApproximateCreationDateTime.Unix(), gocheck.Equals, int64(1540000000))
+	c.Check(records[1].Dynamodb.OldImage, gocheck.IsNil)
+}
@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	simplejson "github.com/bitly/go-simplejson"
 )
@@ -30,8 +35,11 @@ type ProjectionT struct {
 }
 
 type GlobalSecondaryIndexT struct {
+	Backfilling           bool
+	IndexArn              string
 	IndexName             string
 	IndexSizeBytes        int64
+	IndexStatus           string
 	ItemCount             int64
 	KeySchema             []KeySchemaT
 	Projection            ProjectionT
@@ -39,6 +47,7 @@ type GlobalSecondaryIndexT struct {
 }
 
 type LocalSecondaryIndexT struct {
+	IndexArn       string
 	IndexName      string
 	IndexSizeBytes int64
 	ItemCount      int64
@@ -47,28 +56,107 @@ type LocalSecondaryIndexT struct {
 }
 
 type ProvisionedThroughputT struct {
+	LastDecreaseDateTime   EpochTime
+	LastIncreaseDateTime   EpochTime
 	NumberOfDecreasesToday int64
 	ReadCapacityUnits      int64
 	WriteCapacityUnits     int64
 }
 
+type BillingModeSummaryT struct {
+	BillingMode                       BillingMode
+	LastUpdateToPayPerRequestDateTime EpochTime
+}
+
+type SSEDescriptionT struct {
+	KMSMasterKeyArn string
+	SSEType         string
+	Status          string
+}
+
 type TableDescriptionT struct {
 	AttributeDefinitions   []AttributeDefinitionT
-	CreationDateTime       float64
+	BillingMode            BillingMode `json:",omitempty"` // only sent by CreateTable
+	BillingModeSummary     *BillingModeSummaryT
+	CreationDateTime       EpochTime
 	ItemCount              int64
 	KeySchema              []KeySchemaT
+	LatestStreamArn        string
+	LatestStreamLabel      string
 	LocalSecondaryIndexes  []LocalSecondaryIndexT
 	GlobalSecondaryIndexes []GlobalSecondaryIndexT
 	ProvisionedThroughput  ProvisionedThroughputT
+	SSEDescription         *SSEDescriptionT
+	StreamSpecification    *StreamSpecificationT
+	TableArn               string
+	TableId                string
 	TableName              string
 	TableSizeBytes         int64
 	TableStatus            string
+	Tags                   []TagT `json:",omitempty"` // only sent by CreateTable
+}
+
+// EpochTime is a timestamp that DynamoDB encodes as fractional seconds
+// since the Unix epoch. The zero value is encoded as null.
+type EpochTime struct {
+	time.Time
+}
+
+func (t EpochTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	seconds := float64(t.UnixNano()) / float64(time.Second)
+	return []byte(strconv.FormatFloat(seconds, 'f', -1, 64)), nil
+}
+
+func (t *EpochTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		t.Time = time.Time{}
+		return nil
+	}
+	seconds, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("Invalid epoch timestamp %s", data)
+	}
+	whole, frac := math.Modf(seconds)
+	t.Time = time.Unix(int64(whole), int64(frac*float64(time.Second))).UTC()
+	return nil
+}
+
+type StreamSpecificationT struct {
+	StreamEnabled  bool
+	StreamViewType StreamViewType `json:",omitempty"`
+}
+
+// GlobalSecondaryIndexUpdateT describes one change to a table's global
+// secondary indexes. Exactly one of Create, Update or Delete should be set;
+// Update only needs IndexName and ProvisionedThroughput, Delete only IndexName.
+type GlobalSecondaryIndexUpdateT struct {
+	Create *GlobalSecondaryIndexT
+	Update *GlobalSecondaryIndexT
+	Delete *GlobalSecondaryIndexT
+}
+
+// UpdateTableT holds the parameters of an UpdateTable call. Nil and zero
+// fields are left unchanged on the table.
+type UpdateTableT struct {
+	TableName                   string
+	AttributeDefinitions        []AttributeDefinitionT // required when creating an index
+	BillingMode                 BillingMode
+	ProvisionedThroughput       *ProvisionedThroughputT
+	GlobalSecondaryIndexUpdates []GlobalSecondaryIndexUpdateT
+	StreamSpecification         *StreamSpecificationT
 }
 
 type describeTableResponse struct {
 	Table TableDescriptionT
 }
 
+type tableDescriptionResponse struct {
+	TableDescription TableDescriptionT
+}
+
 func findAttributeDefinitionByName(ads []AttributeDefinitionT, name string) *AttributeDefinitionT {
 	for _, a := range ads {
 		if a.Name == name {
@@ -117,28 +205,62 @@ func (s *Server) NewTable(name string, key PrimaryKey) *Table {
 	return &Table{s, name, key}
 }
 
+// OpenTable is NewTable for code that takes a TableAPI, so that tests can
+// hand it a mock instead.
+func (s *Server) OpenTable(name string, key PrimaryKey) TableAPI {
+	return s.NewTable(name, key)
+}
+
+// ListTables returns the names of every table in the region, following
+// LastEvaluatedTableName across pages.
 func (s *Server) ListTables() ([]string, error) {
 	var tables []string
+	var startTableName string
+
+	for {
+		page, lastTableName, err := s.ListTablesPage(0, startTableName)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, page...)
+
+		if lastTableName == "" {
+			return tables, nil
+		}
+		startTableName = lastTableName
+	}
+}
+
+// ListTablesPage issues a single ListTables request. A limit of zero uses
+// the server default and an empty exclusiveStartTableName starts from the
+// beginning. The returned table name is empty on the last page.
+func (s *Server) ListTablesPage(limit int64, exclusiveStartTableName string) ([]string, string, error) {
+	var tables []string
 
 	query := NewEmptyQuery()
+	if limit > 0 {
+		query.AddLimit(limit)
+	}
+	if exclusiveStartTableName != "" {
+		query.AddExclusiveStartTableName(exclusiveStartTableName)
+	}
 
 	jsonResponse, err := s.queryServer(target("ListTables"), query)
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	json, err := simplejson.NewJson(jsonResponse)
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	response, err := json.Get("TableNames").Array()
 
 	if err != nil {
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
+		return nil, "", unexpectedResponse(jsonResponse)
 	}
 
 	for _, value := range response {
@@ -147,45 +269,93 @@ func (s *Server) ListTables() ([]string, error) {
 		}
 	}
 
-	return tables, nil
+	return tables, json.Get("LastEvaluatedTableName").MustString(), nil
+}
+
+// ListTablesWithPrefix returns the names of every table starting with prefix.
+func (s *Server) ListTablesWithPrefix(prefix string) ([]string, error) {
+	return s.listTablesFiltered(func(name string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+}
+
+// ListTablesMatching returns the names of every table matched by pattern.
+func (s *Server) ListTablesMatching(pattern *regexp.Regexp) ([]string, error) {
+	return s.listTablesFiltered(pattern.MatchString)
+}
+
+func (s *Server) listTablesFiltered(keep func(string) bool) ([]string, error) {
+	tables, err := s.ListTables()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []string
+	for _, t := range tables {
+		if keep(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
 }
 
-func (s *Server) CreateTable(tableDescription TableDescriptionT) (string, error) {
+func (s *Server) CreateTable(tableDescription TableDescriptionT) (*TableDescriptionT, error) {
 	query := NewEmptyQuery()
 	query.AddCreateRequestTable(tableDescription)
 
 	jsonResponse, err := s.queryServer(target("CreateTable"), query)
-
 	if err != nil {
-		return "unknown", err
+		return nil, err
 	}
 
-	json, err := simplejson.NewJson(jsonResponse)
-
+	var r tableDescriptionResponse
+	err = json.Unmarshal(jsonResponse, &r)
 	if err != nil {
-		return "unknown", err
+		return nil, err
 	}
 
-	return json.Get("TableDescription").Get("TableStatus").MustString(), nil
+	return &r.TableDescription, nil
 }
 
-func (s *Server) DeleteTable(tableDescription TableDescriptionT) (string, error) {
+func (s *Server) DeleteTable(tableDescription TableDescriptionT) (*TableDescriptionT, error) {
 	query := NewEmptyQuery()
 	query.AddDeleteRequestTable(tableDescription)
 
 	jsonResponse, err := s.queryServer(target("DeleteTable"), query)
+	if err != nil {
+		return nil, err
+	}
 
+	var r tableDescriptionResponse
+	err = json.Unmarshal(jsonResponse, &r)
 	if err != nil {
-		return "unknown", err
+		return nil, err
 	}
 
-	json, err := simplejson.NewJson(jsonResponse)
+	return &r.TableDescription, nil
+}
+
+func (t *Table) Update(update UpdateTableT) (*TableDescriptionT, error) {
+	update.TableName = t.Name
+	return t.Server.UpdateTable(update)
+}
+
+func (s *Server) UpdateTable(update UpdateTableT) (*TableDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.AddUpdateRequestTable(update)
 
+	jsonResponse, err := s.queryServer(target("UpdateTable"), q)
 	if err != nil {
-		return "unknown", err
+		return nil, err
+	}
+
+	var r tableDescriptionResponse
+	err = json.Unmarshal(jsonResponse, &r)
+	if err != nil {
+		return nil, err
 	}
 
-	return json.Get("TableDescription").Get("TableStatus").MustString(), nil
+	return &r.TableDescription, nil
 }
 
 func (t *Table) DescribeTable() (*TableDescriptionT, error) {
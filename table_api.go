@@ -0,0 +1,69 @@
+package ddbomb
+
+import (
+	"context"
+	"time"
+)
+
+// ItemReader reads single items from a table.
+type ItemReader interface {
+	GetItem(key *Key) (map[string]Attribute, error)
+	GetItemConsistent(key *Key, consistentRead bool) (map[string]Attribute, error)
+}
+
+// ItemWriter writes, modifies and deletes single items of a table.
+type ItemWriter interface {
+	PutItem(hashKey string, rangeKey string, attributes []Attribute) (bool, error)
+	ConditionalPutItem(hashKey, rangeKey string, attributes, expected []Attribute) (bool, error)
+	DeleteItem(key *Key) (bool, error)
+	ConditionalDeleteItem(key *Key, expected []Attribute) (bool, error)
+	AddAttributes(key *Key, attributes []Attribute) (bool, error)
+	UpdateAttributes(key *Key, attributes []Attribute) (bool, error)
+	DeleteAttributes(key *Key, attributes []Attribute) (bool, error)
+	ConditionalAddAttributes(key *Key, attributes, expected []Attribute) (bool, error)
+	ConditionalUpdateAttributes(key *Key, attributes, expected []Attribute) (bool, error)
+	ConditionalDeleteAttributes(key *Key, attributes, expected []Attribute) (bool, error)
+}
+
+// Querier runs queries and scans against a table and its indexes.
+type Querier interface {
+	Query(attributeComparisons ...AttributeComparison) ([]map[string]Attribute, error)
+	QueryOnIndex(indexName string, attributeComparisons ...AttributeComparison) ([]map[string]Attribute, error)
+	LimitedQuery(attributeComparisons []AttributeComparison, limit int64) ([]map[string]Attribute, error)
+	LimitedQueryOnIndex(attributeComparisons []AttributeComparison, indexName string, limit int64) ([]map[string]Attribute, error)
+	CountQuery(attributeComparisons []AttributeComparison) (int64, error)
+	FetchResults(query *Query) ([]map[string]Attribute, error)
+	Scan(attributeComparisons ...AttributeComparison) ([]map[string]Attribute, error)
+	ParallelScan(attributeComparisons []AttributeComparison, segment int, totalSegments int) ([]map[string]Attribute, error)
+	ScanWithOptions(opts *ScanOptions) ([]map[string]Attribute, error)
+	ScanPage(opts *ScanOptions) ([]map[string]Attribute, map[string]interface{}, error)
+	CountScan(attributeComparisons ...AttributeComparison) (int64, error)
+	CountScanWithOptions(opts *ScanOptions) (int64, error)
+}
+
+// TableAPI is everything that can be done through a Table. BatchGetItems
+// and BatchWriteItems are left out: the requests they build span tables
+// and are sent by the Server, so they need the concrete *Table.
+type TableAPI interface {
+	ItemReader
+	ItemWriter
+	Querier
+
+	DescribeTable() (*TableDescriptionT, error)
+	Update(update UpdateTableT) (*TableDescriptionT, error)
+	WaitUntilActive(ctx context.Context) (*TableDescriptionT, error)
+	WaitUntilDeleted(ctx context.Context) error
+	WaitForIndexActive(ctx context.Context, indexName string) (*TableDescriptionT, error)
+
+	EnableTTL(attributeName string) error
+	DisableTTL() error
+	DescribeTTL() (*TimeToLiveDescriptionT, error)
+
+	CreateBackup(backupName string) (*BackupDetailsT, error)
+	ListBackups() ([]BackupSummaryT, error)
+	RestoreToPointInTime(targetTableName string, restoreDateTime time.Time) (*TableDescriptionT, error)
+	UpdateContinuousBackups(pointInTimeRecoveryEnabled bool) (*ContinuousBackupsDescriptionT, error)
+	DescribeContinuousBackups() (*ContinuousBackupsDescriptionT, error)
+}
+
+var _ TableAPI = (*Table)(nil)
@@ -1,6 +1,8 @@
 package ddbomb_test
 
 import (
+	"time"
+
 	"github.com/ryansb/dynamodbomb"
 	"launchpad.net/gocheck"
 )
@@ -11,9 +13,8 @@ type TableSuite struct {
 }
 
 func (s *TableSuite) SetUpSuite(c *gocheck.C) {
-	setUpAuth(c)
 	s.DynamoDBTest.TableDescriptionT = s.TableDescriptionT
-	s.server = &ddbomb.Server{dynamodb_auth, dynamodb_region}
+	s.server = setUpServer(c)
 	pk, err := s.TableDescriptionT.BuildPrimaryKey()
 	if err != nil {
 		c.Skip(err.Error())
@@ -45,15 +46,16 @@ var table_suite = &TableSuite{
 var _ = gocheck.Suite(table_suite)
 
 func (s *TableSuite) TestCreateListTable(c *gocheck.C) {
-	status, err := s.server.CreateTable(s.TableDescriptionT)
+	desc, err := s.server.CreateTable(s.TableDescriptionT)
 	if err != nil {
 		c.Fatal(err)
 	}
-	if status != "ACTIVE" && status != "CREATING" {
+	c.Check(desc.TableName, gocheck.Equals, s.TableDescriptionT.TableName)
+	if desc.TableStatus != "ACTIVE" && desc.TableStatus != "CREATING" {
 		c.Error("Expect status to be ACTIVE or CREATING")
 	}
 
-	s.WaitUntilStatus(c, "ACTIVE")
+	s.WaitUntilActive(c)
 
 	tables, err := s.server.ListTables()
 	if err != nil {
@@ -62,3 +64,111 @@ func (s *TableSuite) TestCreateListTable(c *gocheck.C) {
 	c.Check(len(tables), gocheck.Not(gocheck.Equals), 0)
 	c.Check(findTableByName(tables, s.TableDescriptionT.TableName), gocheck.Equals, true)
 }
+
+type OfflineTableSuite struct{}
+
+var _ = gocheck.Suite(&OfflineTableSuite{})
+
+func (s *OfflineTableSuite) TestDescribeTableDecoding(c *gocheck.C) {
+	ts, server := cannedServer(`
+{
+  "Table": {
+    "AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}],
+    "BillingModeSummary": {
+      "BillingMode": "PAY_PER_REQUEST",
+      "LastUpdateToPayPerRequestDateTime": 1540000000
+    },
+    "CreationDateTime": 1539999999.5,
+    "GlobalSecondaryIndexes": [{
+      "Backfilling": true,
+      "IndexArn": "arn:aws:dynamodb:us-east-1:123456789012:table/sites/index/path-index",
+      "IndexName": "path-index",
+      "IndexStatus": "CREATING"
+    }],
+    "KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}],
+    "LatestStreamArn": "arn:aws:dynamodb:us-east-1:123456789012:table/sites/stream/2018-10-20T01:46:40.000",
+    "LatestStreamLabel": "2018-10-20T01:46:40.000",
+    "ProvisionedThroughput": {
+      "LastIncreaseDateTime": 1540000100,
+      "NumberOfDecreasesToday": 0,
+      "ReadCapacityUnits": 0,
+      "WriteCapacityUnits": 0
+    },
+    "SSEDescription": {"SSEType": "KMS", "Status": "ENABLED"},
+    "StreamSpecification": {"StreamEnabled": true, "StreamViewType": "NEW_IMAGE"},
+    "TableArn": "arn:aws:dynamodb:us-east-1:123456789012:table/sites",
+    "TableId": "6f2c8a3e-6b7c-4d2e-9f3a-1c2b3d4e5f60",
+    "TableName": "sites",
+    "TableStatus": "ACTIVE"
+  }
+}`)
+	defer ts.Close()
+
+	desc, err := server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+
+	c.Check(desc.TableArn, gocheck.Equals, "arn:aws:dynamodb:us-east-1:123456789012:table/sites")
+	c.Check(desc.TableId, gocheck.Equals, "6f2c8a3e-6b7c-4d2e-9f3a-1c2b3d4e5f60")
+	c.Check(desc.CreationDateTime.Time, gocheck.Equals, time.Unix(1539999999, 5e8).UTC())
+	c.Check(desc.BillingModeSummary.BillingMode, gocheck.Equals, ddbomb.BILLING_PAY_PER_REQUEST)
+	c.Check(desc.BillingModeSummary.LastUpdateToPayPerRequestDateTime.Unix(), gocheck.Equals, int64(1540000000))
+	c.Check(desc.StreamSpecification, gocheck.DeepEquals, &ddbomb.StreamSpecificationT{true, ddbomb.STREAM_NEW_IMAGE})
+	c.Check(desc.LatestStreamLabel, gocheck.Equals, "2018-10-20T01:46:40.000")
+	c.Check(desc.SSEDescription.Status, gocheck.Equals, "ENABLED")
+	c.Check(desc.ProvisionedThroughput.LastIncreaseDateTime.Unix(), gocheck.Equals, int64(1540000100))
+	c.Check(desc.ProvisionedThroughput.LastDecreaseDateTime.IsZero(), gocheck.Equals, true)
+	c.Check(desc.GlobalSecondaryIndexes[0].IndexStatus, gocheck.Equals, "CREATING")
+	c.Check(desc.GlobalSecondaryIndexes[0].Backfilling, gocheck.Equals, true)
+}
+
+func (s *OfflineTableSuite) TestListTablesFollowsPages(c *gocheck.C) {
+	ts, server := cannedServer(
+		`{"TableNames": ["prod-sites", "staging-sites"], "LastEvaluatedTableName": "staging-sites"}`,
+		`{"TableNames": ["staging-users"]}`,
+	)
+	defer ts.Close()
+
+	tables, err := server.ListTablesWithPrefix("staging-")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(tables, gocheck.DeepEquals, []string{"staging-sites", "staging-users"})
+}
+
+func (s *OfflineTableSuite) TestDisableTTL(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(cannedHandler(
+		`{"TimeToLiveDescription": {"AttributeName": "expires_at", "TimeToLiveStatus": "ENABLED"}}`,
+		`{"TimeToLiveSpecification": {"AttributeName": "expires_at", "Enabled": false}}`,
+	), &requests))
+	defer ts.Close()
+
+	table := server.NewTable("sessions", ddbomb.PrimaryKey{})
+	c.Assert(table.DisableTTL(), gocheck.IsNil)
+	c.Check(requests, gocheck.DeepEquals, []string{
+		`DynamoDB_20120810.DescribeTimeToLive {"TableName":"sessions"}`,
+		`DynamoDB_20120810.UpdateTimeToLive {"TableName":"sessions","TimeToLiveSpecification":{"AttributeName":"expires_at","Enabled":false}}`,
+	})
+}
+
+func (s *OfflineTableSuite) TestTagging(c *gocheck.C) {
+	var requests []string
+	ts, server := newTestServer(recordTargets(cannedHandler(
+		`{"TableDescription": {"TableName": "sites", "TableStatus": "CREATING"}}`,
+		`{"Tags": [{"Key": "team", "Value": "web"}], "NextToken": "t1"}`,
+		`{"Tags": [{"Key": "env", "Value": "prod"}]}`,
+	), &requests))
+	defer ts.Close()
+
+	_, err := server.CreateTable(ddbomb.TableDescriptionT{
+		TableName:   "sites",
+		BillingMode: ddbomb.BILLING_PAY_PER_REQUEST,
+		Tags:        []ddbomb.TagT{{"team", "web"}},
+	})
+	c.Assert(err, gocheck.IsNil)
+
+	tags, err := server.ListTagsOfResource("arn:aws:dynamodb:us-east-1:123456789012:table/sites")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(tags, gocheck.DeepEquals, []ddbomb.TagT{{"team", "web"}, {"env", "prod"}})
+
+	c.Check(requests[0], gocheck.Matches, `DynamoDB_20120810.CreateTable .*"Tags":\[{"Key":"team","Value":"web"}\].*`)
+	c.Check(requests[2], gocheck.Equals, `DynamoDB_20120810.ListTagsOfResource {"NextToken":"t1","ResourceArn":"arn:aws:dynamodb:us-east-1:123456789012:table/sites"}`)
+}
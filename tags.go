@@ -0,0 +1,53 @@
+package ddbomb
+
+type TagT struct {
+	Key   string
+	Value string
+}
+
+type listTagsOfResourceResponse struct {
+	NextToken string
+	Tags      []TagT
+}
+
+// TagResource adds or overwrites tags on a table, index or backup. The
+// resource is identified by its ARN, as found in TableDescriptionT.TableArn.
+func (s *Server) TagResource(resourceArn string, tags []TagT) error {
+	q := NewEmptyQuery()
+	q.AddResourceArn(resourceArn)
+	q.AddTags(tags)
+
+	_, err := s.queryServer(target("TagResource"), q)
+	return err
+}
+
+func (s *Server) UntagResource(resourceArn string, tagKeys []string) error {
+	q := NewEmptyQuery()
+	q.AddResourceArn(resourceArn)
+	q.AddTagKeys(tagKeys)
+
+	_, err := s.queryServer(target("UntagResource"), q)
+	return err
+}
+
+// ListTagsOfResource returns every tag on the resource, following NextToken
+// across pages.
+func (s *Server) ListTagsOfResource(resourceArn string) ([]TagT, error) {
+	var tags []TagT
+
+	q := NewEmptyQuery()
+	q.AddResourceArn(resourceArn)
+
+	for {
+		var r listTagsOfResourceResponse
+		if err := s.queryServerInto(target("ListTagsOfResource"), q, &r); err != nil {
+			return nil, err
+		}
+		tags = append(tags, r.Tags...)
+
+		if r.NextToken == "" {
+			return tags, nil
+		}
+		q.AddNextToken(r.NextToken)
+	}
+}
@@ -0,0 +1,179 @@
+package ddbomb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AdaptiveThrottle slows down the requests to the tables DynamoDB throttles,
+// so that retries don't make matters worse. Set it as Server.Throttle:
+//
+//	server.Throttle = &ddbomb.AdaptiveThrottle{}
+//
+// Requests to a table are sent as fast as they come until one is throttled.
+// The send rate to that table is then cut to Backoff times the rate it had
+// over the last second, and grows by Increase requests per second with
+// every request that goes through. Once RecoveryTime passes without
+// throttles, the table is no longer limited.
+type AdaptiveThrottle struct {
+	// Backoff is the factor the send rate is multiplied by on a throttle;
+	// 0.7 when zero.
+	Backoff float64
+
+	// Increase is how many requests per second the send rate grows by on
+	// every request that isn't throttled; 1 when zero.
+	Increase float64
+
+	// MinRate is the lowest send rate, in requests per second; 1 when zero.
+	MinRate float64
+
+	// RecoveryTime is how long without throttles it takes to lift the limit
+	// on a table; 10 seconds when zero.
+	RecoveryTime time.Duration
+
+	// OnRateChange, when set, is called with the send rate of table, in
+	// requests per second, whenever it changes. A rate of 0 means the table
+	// is no longer limited.
+	OnRateChange func(table string, rate float64)
+
+	mu     sync.Mutex
+	tables map[string]*throttleState
+}
+
+type throttleState struct {
+	bucket        tokenBucket // its rate is 0 while the table isn't limited
+	lastThrottle  time.Time
+	window        time.Time // start of the current one second window
+	sent, sentPre int       // requests sent in the current and previous windows
+}
+
+func (t *AdaptiveThrottle) config() (backoff, increase, minRate float64, recovery time.Duration) {
+	backoff, increase, minRate, recovery = t.Backoff, t.Increase, t.MinRate, t.RecoveryTime
+	if backoff <= 0 || backoff >= 1 {
+		backoff = 0.7
+	}
+	if increase <= 0 {
+		increase = 1
+	}
+	if minRate <= 0 {
+		minRate = 1
+	}
+	if recovery <= 0 {
+		recovery = 10 * time.Second
+	}
+	return
+}
+
+func (t *AdaptiveThrottle) state(table string, now time.Time) *throttleState {
+	if t.tables == nil {
+		t.tables = map[string]*throttleState{}
+	}
+	state := t.tables[table]
+	if state == nil {
+		state = &throttleState{window: now}
+		t.tables[table] = state
+	}
+	if elapsed := now.Sub(state.window); elapsed >= time.Second {
+		state.sentPre = state.sent
+		if elapsed >= 2*time.Second {
+			state.sentPre = 0
+		}
+		state.sent = 0
+		state.window = now
+	}
+	return state
+}
+
+// Rate returns the send rate table is limited to, in requests per second,
+// or 0 when it isn't limited.
+func (t *AdaptiveThrottle) Rate(table string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if state := t.tables[table]; state != nil {
+		return state.bucket.rate
+	}
+	return 0
+}
+
+// wait blocks until a request may be sent to each of tables.
+func (t *AdaptiveThrottle) wait(ctx context.Context, tables []string) error {
+	t.mu.Lock()
+	now := time.Now()
+	_, _, _, recovery := t.config()
+	var wait time.Duration
+	var lifted []string
+	for _, table := range tables {
+		state := t.state(table, now)
+		state.sent++
+		if state.bucket.rate == 0 {
+			continue
+		}
+		if now.Sub(state.lastThrottle) >= recovery {
+			state.bucket = tokenBucket{}
+			lifted = append(lifted, table)
+			continue
+		}
+		if w := state.bucket.take(1, now); w > wait {
+			wait = w
+		}
+	}
+	t.mu.Unlock()
+
+	for _, table := range lifted {
+		t.rateChanged(table, 0)
+	}
+	if wait == 0 {
+		return nil
+	}
+	return sleep(ctx, wait)
+}
+
+// observe adapts the send rate to tables after a request was or wasn't
+// throttled.
+func (t *AdaptiveThrottle) observe(tables []string, throttled bool) {
+	t.mu.Lock()
+	now := time.Now()
+	backoff, increase, minRate, _ := t.config()
+	changed := map[string]float64{}
+	for _, table := range tables {
+		state := t.state(table, now)
+		rate := state.bucket.rate
+		switch {
+		case throttled:
+			state.lastThrottle = now
+			if rate == 0 {
+				rate = float64(state.sent)
+				if state.sentPre > state.sent {
+					rate = float64(state.sentPre)
+				}
+			}
+			rate *= backoff
+			if rate < minRate {
+				rate = minRate
+			}
+		case rate > 0:
+			rate += increase
+		default:
+			continue
+		}
+		newlyLimited := state.bucket.rate == 0
+		state.bucket.setRate(rate, now)
+		if newlyLimited {
+			state.bucket.tokens = 0
+		}
+		changed[table] = rate
+	}
+	t.mu.Unlock()
+
+	for table, rate := range changed {
+		t.rateChanged(table, rate)
+	}
+}
+
+func (t *AdaptiveThrottle) rateChanged(table string, rate float64) {
+	if t.OnRateChange != nil {
+		t.OnRateChange(table, rate)
+	}
+}
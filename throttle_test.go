@@ -0,0 +1,63 @@
+package ddbomb_test
+
+import (
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type ThrottleSuite struct{}
+
+var _ = gocheck.Suite(&ThrottleSuite{})
+
+type rateChange struct {
+	table string
+	rate  float64
+}
+
+const describeSites = `{"Table": {"TableName": "sites", "TableStatus": "ACTIVE"}}`
+
+func (s *ThrottleSuite) TestThrottleCutsRate(c *gocheck.C) {
+	ts, server := newTestServer(failingHandler(1, 400, "ProvisionedThroughputExceededException", describeSites))
+	defer ts.Close()
+	server.MaxRetries = 1
+
+	var changes []rateChange
+	server.Throttle = &ddbomb.AdaptiveThrottle{
+		MinRate:      5,
+		OnRateChange: func(table string, rate float64) { changes = append(changes, rateChange{table, rate}) },
+	}
+
+	// The retry waits for the limited bucket to refill: 1/5s.
+	start := time.Now()
+	_, err := server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(time.Since(start) >= 150*time.Millisecond, gocheck.Equals, true)
+
+	c.Check(changes, gocheck.DeepEquals, []rateChange{{"sites", 5}, {"sites", 6}})
+	c.Check(server.Throttle.Rate("sites"), gocheck.Equals, 6.0)
+	c.Check(server.Throttle.Rate("other"), gocheck.Equals, 0.0)
+}
+
+func (s *ThrottleSuite) TestThrottleRecovers(c *gocheck.C) {
+	ts, server := newTestServer(failingHandler(1, 400, "ThrottlingException", describeSites))
+	defer ts.Close()
+
+	var changes []rateChange
+	server.Throttle = &ddbomb.AdaptiveThrottle{
+		MinRate:      100,
+		RecoveryTime: 50 * time.Millisecond,
+		OnRateChange: func(table string, rate float64) { changes = append(changes, rateChange{table, rate}) },
+	}
+
+	_, err := server.DescribeTable("sites")
+	c.Check(ddbomb.IsThrottle(err), gocheck.Equals, true)
+	c.Check(server.Throttle.Rate("sites"), gocheck.Equals, 100.0)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = server.DescribeTable("sites")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(changes, gocheck.DeepEquals, []rateChange{{"sites", 100}, {"sites", 0}})
+	c.Check(server.Throttle.Rate("sites"), gocheck.Equals, 0.0)
+}
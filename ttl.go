@@ -0,0 +1,59 @@
+package ddbomb
+
+import (
+	"encoding/json"
+)
+
+type TimeToLiveDescriptionT struct {
+	AttributeName    string
+	TimeToLiveStatus string // ENABLING, DISABLING, ENABLED or DISABLED
+}
+
+type describeTimeToLiveResponse struct {
+	TimeToLiveDescription TimeToLiveDescriptionT
+}
+
+// EnableTTL turns on Time to Live for the table, expiring items once the
+// epoch seconds stored in attributeName have passed. Struct fields tagged
+// with the "ttl" option are marshalled in that format.
+func (t *Table) EnableTTL(attributeName string) error {
+	return t.updateTTL(attributeName, true)
+}
+
+// DisableTTL turns off Time to Live for whichever attribute it is currently
+// enabled on.
+func (t *Table) DisableTTL() error {
+	desc, err := t.DescribeTTL()
+	if err != nil {
+		return err
+	}
+	if desc.AttributeName == "" {
+		return nil
+	}
+	return t.updateTTL(desc.AttributeName, false)
+}
+
+func (t *Table) DescribeTTL() (*TimeToLiveDescriptionT, error) {
+	q := NewQuery(t)
+
+	jsonResponse, err := t.Server.queryServer(target("DescribeTimeToLive"), q)
+	if err != nil {
+		return nil, err
+	}
+
+	var r describeTimeToLiveResponse
+	err = json.Unmarshal(jsonResponse, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.TimeToLiveDescription, nil
+}
+
+func (t *Table) updateTTL(attributeName string, enabled bool) error {
+	q := NewQuery(t)
+	q.AddTimeToLiveSpecification(attributeName, enabled)
+
+	_, err := t.Server.queryServer(target("UpdateTimeToLive"), q)
+	return err
+}
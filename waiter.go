@@ -0,0 +1,124 @@
+package ddbomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The waiters poll DescribeTable with exponential backoff between these
+// two delays until the table reaches the wanted state or ctx is done.
+const (
+	waiterMinDelay = 500 * time.Millisecond
+	waiterMaxDelay = 20 * time.Second
+)
+
+// ErrIndexNotFound is returned by WaitForIndexActive when the table is
+// ACTIVE and has no global secondary index of that name.
+var ErrIndexNotFound = errors.New("Index not found")
+
+func (t *Table) WaitUntilActive(ctx context.Context) (*TableDescriptionT, error) {
+	return t.Server.WaitUntilTableActive(ctx, t.Name)
+}
+
+func (t *Table) WaitUntilDeleted(ctx context.Context) error {
+	return t.Server.WaitUntilTableDeleted(ctx, t.Name)
+}
+
+func (t *Table) WaitForIndexActive(ctx context.Context, indexName string) (*TableDescriptionT, error) {
+	return t.Server.WaitForIndexActive(ctx, t.Name, indexName)
+}
+
+// WaitUntilTableActive blocks until the table and all of its global
+// secondary indexes are ACTIVE, and returns the final description.
+func (s *Server) WaitUntilTableActive(ctx context.Context, name string) (*TableDescriptionT, error) {
+	var desc *TableDescriptionT
+	err := s.waitForTable(ctx, name, func(d *TableDescriptionT, err error) (bool, error) {
+		if err != nil {
+			// A freshly created table may not be visible yet.
+			return false, ignoreResourceNotFound(err)
+		}
+		desc = d
+		return tableActive(d), nil
+	})
+	return desc, err
+}
+
+// WaitUntilTableDeleted blocks until DescribeTable reports that the table
+// no longer exists.
+func (s *Server) WaitUntilTableDeleted(ctx context.Context, name string) error {
+	return s.waitForTable(ctx, name, func(d *TableDescriptionT, err error) (bool, error) {
+		if err != nil {
+			return errors.Is(err, ErrResourceNotFound), ignoreResourceNotFound(err)
+		}
+		return false, nil
+	})
+}
+
+// WaitForIndexActive blocks until the named global secondary index is
+// ACTIVE, which for a newly added index includes the end of its backfill.
+func (s *Server) WaitForIndexActive(ctx context.Context, tableName, indexName string) (*TableDescriptionT, error) {
+	var desc *TableDescriptionT
+	err := s.waitForTable(ctx, tableName, func(d *TableDescriptionT, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		desc = d
+		for _, ind := range d.GlobalSecondaryIndexes {
+			if ind.IndexName == indexName {
+				return ind.IndexStatus == "ACTIVE", nil
+			}
+		}
+		// An index being added shows up as soon as the table is UPDATING.
+		if d.TableStatus == "ACTIVE" {
+			return false, fmt.Errorf("%w: %s on table %s", ErrIndexNotFound, indexName, tableName)
+		}
+		return false, nil
+	})
+	return desc, err
+}
+
+// waitForTable calls done with the result of each DescribeTable until it
+// reports the wait is over or returns an error.
+func (s *Server) waitForTable(ctx context.Context, name string, done func(*TableDescriptionT, error) (bool, error)) error {
+	delay := waiterMinDelay
+	for {
+		finished, err := done(s.WithContext(ctx).DescribeTable(name))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || finished {
+			return err
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+
+		delay *= 2
+		if delay > waiterMaxDelay {
+			delay = waiterMaxDelay
+		}
+	}
+}
+
+func tableActive(d *TableDescriptionT) bool {
+	if d.TableStatus != "ACTIVE" {
+		return false
+	}
+	for _, ind := range d.GlobalSecondaryIndexes {
+		// Older DynamoDB Local releases leave IndexStatus out entirely.
+		if ind.IndexStatus != "" && ind.IndexStatus != "ACTIVE" {
+			return false
+		}
+	}
+	return true
+}
+
+func ignoreResourceNotFound(err error) error {
+	if errors.Is(err, ErrResourceNotFound) {
+		return nil
+	}
+	return err
+}
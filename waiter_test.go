@@ -0,0 +1,61 @@
+package ddbomb_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ryansb/dynamodbomb"
+	"launchpad.net/gocheck"
+)
+
+type WaiterSuite struct{}
+
+var _ = gocheck.Suite(&WaiterSuite{})
+
+func (s *WaiterSuite) TestWaitUntilTableActive(c *gocheck.C) {
+	ts, server := cannedServer(
+		"",
+		`{"Table":{"TableName":"t","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"i","IndexStatus":"CREATING"}]}}`,
+		`{"Table":{"TableName":"t","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"i","IndexStatus":"ACTIVE"}]}}`,
+	)
+	defer ts.Close()
+
+	desc, err := server.WaitUntilTableActive(context.Background(), "t")
+	c.Assert(err, gocheck.IsNil)
+	c.Check(desc.TableStatus, gocheck.Equals, "ACTIVE")
+	c.Check(desc.GlobalSecondaryIndexes[0].IndexStatus, gocheck.Equals, "ACTIVE")
+}
+
+func (s *WaiterSuite) TestWaitUntilTableDeleted(c *gocheck.C) {
+	ts, server := cannedServer(
+		`{"Table":{"TableName":"t","TableStatus":"DELETING"}}`,
+		"",
+	)
+	defer ts.Close()
+
+	err := server.WaitUntilTableDeleted(context.Background(), "t")
+	c.Check(err, gocheck.IsNil)
+}
+
+func (s *WaiterSuite) TestWaitForMissingIndex(c *gocheck.C) {
+	ts, server := cannedServer(
+		`{"Table":{"TableName":"t","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"i","IndexStatus":"ACTIVE"}]}}`,
+	)
+	defer ts.Close()
+
+	_, err := server.NewTable("t", ddbomb.PrimaryKey{}).WaitForIndexActive(context.Background(), "missing")
+	c.Check(errors.Is(err, ddbomb.ErrIndexNotFound), gocheck.Equals, true)
+}
+
+func (s *WaiterSuite) TestWaitForIndexActiveHonorsDeadline(c *gocheck.C) {
+	ts, server := cannedServer(
+		`{"Table":{"TableName":"t","TableStatus":"UPDATING","GlobalSecondaryIndexes":[{"IndexName":"i","IndexStatus":"CREATING"}]}}`,
+	)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := server.NewTable("t", ddbomb.PrimaryKey{}).WaitForIndexActive(ctx, "i")
+	c.Check(err, gocheck.Equals, context.DeadlineExceeded)
+}